@@ -2,7 +2,11 @@ module github.com/bww/go-mime
 
 go 1.22.3
 
-require github.com/stretchr/testify v1.9.0
+require (
+	github.com/stretchr/testify v1.9.0
+	github.com/yuin/goldmark v1.8.6
+	golang.org/x/text v0.22.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
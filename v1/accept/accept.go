@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/bww/go-mime/v1/internal/lex"
 )
 
 var (
@@ -34,59 +36,10 @@ type MediaType struct {
 	Parameters Parameters
 }
 
-func isWhiteSpaceChar(c byte) bool {
-	// RFC 7230, 3.2.3. Whitespace
-	return c == 0x09 || c == 0x20 // HTAB or SP
-}
-
-func isDigitChar(c byte) bool {
-	// RFC 5234, Appendix B.1. Core Rules
-	return c >= 0x30 && c <= 0x39
-}
-
-func isAlphaChar(c byte) bool {
-	// RFC 5234, Appendix B.1. Core Rules
-	return (c >= 0x41 && c <= 0x5A) || (c >= 0x61 && c <= 0x7A)
-}
-
-func isTokenChar(c byte) bool {
-	// RFC 7230, 3.2.6. Field Value Components
-	return c == '!' || c == '#' || c == '$' || c == '%' || c == '&' || c == '\'' || c == '*' ||
-		c == '+' || c == '-' || c == '.' || c == '^' || c == '_' || c == '`' || c == '|' || c == '~' ||
-		isDigitChar(c) ||
-		isAlphaChar(c)
-}
-
-func isVisibleChar(c byte) bool {
-	// RFC 5234, Appendix B.1. Core Rules
-	return c >= 0x21 && c <= 0x7E
-}
-
-func isObsoleteTextChar(c byte) bool {
-	// RFC 7230, 3.2.6. Field Value Components
-	return c >= 0x80 && c <= 0xFF
-}
-
-func isQuotedTextChar(c byte) bool {
-	// RFC 7230, 3.2.6. Field Value Components
-	return c == 0x09 || c == 0x20 || // HTAB or SP
-		c == 0x21 ||
-		(c >= 0x23 && c <= 0x5B) ||
-		(c >= 0x5D && c <= 0x7E) ||
-		isObsoleteTextChar(c)
-}
-
-func isQuotedPairChar(c byte) bool {
-	// RFC 7230, 3.2.6. Field Value Components
-	return c == 0x09 || c == 0x20 || // HTAB or SP
-		isVisibleChar(c) ||
-		isObsoleteTextChar(c)
-}
-
 func skipWhiteSpaces(s string) string {
 	// RFC 7230, 3.2.3. Whitespace
 	for i := 0; i < len(s); i++ {
-		if !isWhiteSpaceChar(s[i]) {
+		if !lex.IsWhiteSpaceChar(s[i]) {
 			return s[i:]
 		}
 	}
@@ -95,35 +48,45 @@ func skipWhiteSpaces(s string) string {
 }
 
 func consumeToken(s string) (token, remaining string, consumed bool) {
+	token, remaining, consumed = consumeRawToken(s)
+	return strings.ToLower(token), remaining, consumed
+}
+
+func consumeRawToken(s string) (token, remaining string, consumed bool) {
 	// RFC 7230, 3.2.6. Field Value Components
 	for i := 0; i < len(s); i++ {
-		if !isTokenChar(s[i]) {
-			return strings.ToLower(s[:i]), s[i:], i > 0
+		if !lex.IsTokenChar(s[i]) {
+			return s[:i], s[i:], i > 0
 		}
 	}
 
-	return strings.ToLower(s), "", len(s) > 0
+	return s, "", len(s) > 0
 }
 
 func consumeQuotedString(s string) (token, remaining string, consumed bool) {
+	token, remaining, consumed = consumeRawQuotedString(s)
+	return strings.ToLower(token), remaining, consumed
+}
+
+func consumeRawQuotedString(s string) (token, remaining string, consumed bool) {
 	var stringBuilder strings.Builder
 
 	index := 0
 	for ; index < len(s); index++ {
 		if s[index] == '\\' {
 			index++
-			if len(s) <= index || !isQuotedPairChar(s[index]) {
+			if len(s) <= index || !lex.IsQuotedPairChar(s[index]) {
 				return "", s, false
 			}
 			stringBuilder.WriteByte(s[index])
-		} else if isQuotedTextChar(s[index]) {
+		} else if lex.IsQuotedTextChar(s[index]) {
 			stringBuilder.WriteByte(s[index])
 		} else {
 			break
 		}
 	}
 
-	return strings.ToLower(stringBuilder.String()), s[index:], true
+	return stringBuilder.String(), s[index:], true
 }
 
 func consumeType(s string) (string, string, string, bool) {
@@ -178,7 +141,13 @@ func consumeParameter(s string) (string, string, string, bool) {
 	if len(s) > 0 && s[0] == '"' {
 		s = s[1:] // skip the opening quote
 
-		value, s, consumed = consumeQuotedString(s)
+		// Profiles are URIs, which are case sensitive, so unlike other
+		// parameter values they keep their case.
+		if key == "profile" {
+			value, s, consumed = consumeRawQuotedString(s)
+		} else {
+			value, s, consumed = consumeQuotedString(s)
+		}
 		if !consumed {
 			return "", "", s, false
 		}
@@ -245,7 +214,15 @@ func compareMediaTypes(checkMediaType, mediaType MediaType) bool {
 		(checkMediaType.Subtype == "*" || checkMediaType.Subtype == mediaType.Subtype) {
 
 		for checkKey, checkValue := range checkMediaType.Parameters {
-			if value, found := mediaType.Parameters[checkKey]; !found || value != checkValue {
+			value, found := mediaType.Parameters[checkKey]
+			if !found {
+				return false
+			}
+			if checkKey == "profile" {
+				if !compareProfiles(checkValue, value) {
+					return false
+				}
+			} else if value != checkValue {
 				return false
 			}
 		}
@@ -270,28 +247,31 @@ func getPrecedence(checkMediaType, mediaType MediaType) bool {
 	return false
 }
 
-// Parses the string and returns an instance of MediaType struct.
+// Parses the string and returns an instance of MediaType struct. An empty
+// MediaType is returned if the string is invalid and anything following the
+// parameters is ignored; use ParseMediaTypeString to find out why a string
+// could not be parsed.
 func NewMediaType(s string) MediaType {
-	mediaType := MediaType{}
-	var consumed bool
-	mediaType.Type, mediaType.Subtype, s, consumed = consumeType(s)
-	if !consumed {
+	mediaType, _, err := newParser("", s, false).consumeMediaType()
+	if err != nil {
 		return MediaType{}
 	}
 
-	mediaType.Parameters = make(Parameters)
-
-	for len(s) > 0 && s[0] == ';' {
-		s = s[1:] // skip the semicolon
-
-		key, value, remaining, consumed := consumeParameter(s)
-		if !consumed {
-			return MediaType{}
-		}
+	return mediaType
+}
 
-		s = remaining
+// Parses the string as a media type, exactly as ParseMediaType parses a
+// Content-Type header. Unlike NewMediaType, nothing may follow the parameters.
+func ParseMediaTypeString(s string) (MediaType, error) {
+	return newParser("", s, false).parseMediaType()
+}
 
-		mediaType.Parameters[key] = value
+// Like ParseMediaTypeString, but panics if the string is invalid. It is meant
+// for initializing variables with media types known at compile time.
+func MustMediaType(s string) MediaType {
+	mediaType, err := ParseMediaTypeString(s)
+	if err != nil {
+		panic(err)
 	}
 
 	return mediaType
@@ -331,22 +311,58 @@ func ParseMediaType(request *http.Request) (MediaType, error) {
 		return MediaType{}, nil
 	}
 
-	s := contentTypeHeaders[0]
+	return newParser("Content-Type", contentTypeHeaders[0], false).parseMediaType()
+}
+
+// Parses the value of a Content-Type header. When the parser is lenient,
+// invalid parameters and trailing characters are skipped with a warning.
+func (p *parser) parseMediaType() (MediaType, error) {
+	mediaType, s, err := p.consumeMediaType()
+	if err != nil {
+		return MediaType{}, err
+	}
+
+	// there must not be anything left after parsing the header
+	if len(s) > 0 {
+		if !p.lenient {
+			return MediaType{}, p.fail(0, s, expected("a semicolon", s), ErrInvalidMediaType)
+		}
+		p.warn(0, s, "ignored trailing characters", ErrInvalidMediaType)
+	}
+
+	return mediaType, nil
+}
+
+// Consumes a media type and its parameters, returning whatever follows them.
+func (p *parser) consumeMediaType() (MediaType, string, error) {
+	s := p.input
 	mediaType := MediaType{}
 	var consumed bool
-	mediaType.Type, mediaType.Subtype, s, consumed = consumeType(s)
+	var remaining string
+	mediaType.Type, mediaType.Subtype, remaining, consumed = consumeType(s)
 	if !consumed {
-		return MediaType{}, ErrInvalidMediaType
+		return MediaType{}, remaining, p.fail(0, remaining, expected("a type and subtype", remaining), ErrInvalidMediaType)
 	}
+	s = remaining
 
 	mediaType.Parameters = make(Parameters)
 
 	for len(s) > 0 && s[0] == ';' {
 		s = s[1:] // skip the semicolon
 
+		if p.lenient && p.emptyParameter(0, s) {
+			s = skipWhiteSpaces(s)
+			continue
+		}
+
 		key, value, remaining, consumed := consumeParameter(s)
 		if !consumed {
-			return MediaType{}, ErrInvalidParameter
+			if !p.lenient {
+				return MediaType{}, remaining, p.fail(0, remaining, expected("a parameter", remaining), ErrInvalidParameter)
+			}
+			p.warn(0, remaining, "skipped invalid parameter", ErrInvalidParameter)
+			s = skipParameter(remaining)
+			continue
 		}
 
 		s = remaining
@@ -354,106 +370,170 @@ func ParseMediaType(request *http.Request) (MediaType, error) {
 		mediaType.Parameters[key] = value
 	}
 
-	// there must not be anything left after parsing the header
-	if len(s) > 0 {
-		return MediaType{}, ErrInvalidMediaType
-	}
+	return mediaType, s, nil
+}
 
-	return mediaType, nil
+// A media range parsed from an Accept header, along with its weight, any
+// extension parameters and its position in the header.
+type mediaRange struct {
+	mediaType           MediaType
+	extensionParameters Parameters
+	weight              int
+	order               int
 }
 
-// Choses a media type from available media types according to the Accept.
-// Returns the most suitable media type or an error if no type can be selected.
-func MatchAcceptableMediaType(request *http.Request, availableMediaTypes []MediaType) (MediaType, Parameters, error) {
+// Parses the value of an Accept header into the media ranges it lists. When
+// the parser is lenient, invalid ranges are skipped instead of failing the
+// whole header.
+func (p *parser) parseAccept() ([]mediaRange, error) {
 	// RFC 7231, 5.3.2. Accept
-	if len(availableMediaTypes) == 0 {
-		return MediaType{}, Parameters{}, ErrNoAvailableTypeGiven
-	}
+	s := p.input
+	var ranges []mediaRange
 
-	acceptHeaders := request.Header.Values("Accept")
-	if len(acceptHeaders) == 0 {
-		return availableMediaTypes[0], Parameters{}, nil
-	}
-
-	s := acceptHeaders[0]
-
-	weights := make([]struct {
-		mediaType           MediaType
-		extensionParameters Parameters
-		weight              int
-		order               int
-	}, len(availableMediaTypes))
-
-	for mediaTypeCount := 0; len(s) > 0; mediaTypeCount++ {
-		if mediaTypeCount > 0 {
+	mediaTypeCount := 0
+	for first := true; len(s) > 0; first = false {
+		if !first {
 			// every media type after the first one must start with a comma
 			if s[0] != ',' {
 				break
 			}
 			s = s[1:] // skip the comma
+			mediaTypeCount++
 		}
 
-		acceptableMediaType := MediaType{}
-		var consumed bool
-		acceptableMediaType.Type, acceptableMediaType.Subtype, s, consumed = consumeType(s)
-		if !consumed {
-			return MediaType{}, Parameters{}, ErrInvalidMediaType
+		if p.lenient {
+			if remaining := skipWhiteSpaces(s); len(remaining) == 0 || remaining[0] == ',' {
+				p.warn(mediaTypeCount, s, "skipped empty element", ErrInvalidMediaType)
+				s = remaining
+				continue
+			}
 		}
 
-		acceptableMediaType.Parameters = make(Parameters)
-		weight := 1000 // 1.000
+		acceptable, remaining, err := p.consumeMediaRange(s, mediaTypeCount)
+		if err == nil {
+			remaining = skipWhiteSpaces(remaining)
+			if p.lenient && len(remaining) > 0 && remaining[0] != ',' {
+				err = p.fail(mediaTypeCount, remaining, expected("a comma", remaining), ErrInvalidMediaRange)
+			}
+		}
+		if err != nil {
+			if !p.lenient {
+				return nil, err
+			}
+			p.warn(mediaTypeCount, remaining, "skipped invalid media range", err)
+			s = skipElement(remaining)
+			continue
+		}
 
-		// media type parameters
-		for len(s) > 0 && s[0] == ';' {
-			s = s[1:] // skip the semicolon
+		ranges = append(ranges, acceptable)
+		s = remaining
+	}
 
-			var key, value string
-			key, value, s, consumed = consumeParameter(s)
-			if !consumed {
-				return MediaType{}, Parameters{}, ErrInvalidParameter
-			}
+	// there must not be anything left after parsing the header
+	if len(s) > 0 {
+		return nil, p.fail(mediaTypeCount, s, expected("a comma", s), ErrInvalidMediaRange)
+	}
 
-			if key == "q" {
-				weight, consumed = getWeight(value)
-				if !consumed {
-					return MediaType{}, Parameters{}, ErrInvalidWeight
-				}
-				break // "q" parameter separates media type parameters from Accept extension parameters
-			}
+	return ranges, nil
+}
+
+// Consumes a single media range and its parameters from an Accept header. On
+// failure the remaining input starts where the problem was found.
+func (p *parser) consumeMediaRange(s string, mediaTypeCount int) (mediaRange, string, *ParseError) {
+	acceptableMediaType := MediaType{}
+	var consumed bool
+	var remaining string
+	acceptableMediaType.Type, acceptableMediaType.Subtype, remaining, consumed = consumeType(s)
+	if !consumed {
+		if !p.lenient {
+			return mediaRange{}, remaining, p.fail(mediaTypeCount, remaining, expected("a type and subtype", remaining), ErrInvalidMediaType)
+		}
+		if wildcard, ok := consumeBareWildcard(s); ok {
+			remaining = wildcard
+		} else {
+			return mediaRange{}, remaining, p.fail(mediaTypeCount, remaining, expected("a type and subtype", remaining), ErrInvalidMediaType)
+		}
+		p.warn(mediaTypeCount, s, "interpreted \"*\" as \"*/*\"", ErrInvalidMediaType)
+		acceptableMediaType.Type, acceptableMediaType.Subtype = "*", "*"
+	}
+	s = remaining
+
+	acceptableMediaType.Parameters = make(Parameters)
+	weight := 1000 // 1.000
 
-			acceptableMediaType.Parameters[key] = value
+	// media type parameters
+	for len(s) > 0 && s[0] == ';' {
+		s = s[1:] // skip the semicolon
+
+		if p.lenient && p.emptyParameter(mediaTypeCount, s) {
+			s = skipWhiteSpaces(s)
+			continue
 		}
 
-		extensionParameters := make(Parameters)
-		for len(s) > 0 && s[0] == ';' {
-			s = s[1:] // skip the semicolon
+		var key, value string
+		key, value, remaining, consumed = consumeParameter(s)
+		if !consumed {
+			return mediaRange{}, remaining, p.fail(mediaTypeCount, remaining, expected("a parameter", remaining), ErrInvalidParameter)
+		}
 
-			key, value, remaining, consumed := consumeParameter(s)
+		if key == "q" {
+			weight, consumed = getWeight(value)
+			if !consumed && p.lenient && len(value) > 0 && value[0] == '.' {
+				weight, consumed = getWeight("0" + value)
+				if consumed {
+					p.warn(mediaTypeCount, s, "interpreted weight without a leading zero", ErrInvalidWeight)
+				}
+			}
 			if !consumed {
-				return MediaType{}, Parameters{}, ErrInvalidParameter
+				return mediaRange{}, s, p.fail(mediaTypeCount, s, fmt.Sprintf("weight %q is not a number between 0 and 1 with at most three decimals", value), ErrInvalidWeight)
 			}
-
 			s = remaining
+			break // "q" parameter separates media type parameters from Accept extension parameters
+		}
 
-			extensionParameters[key] = value
+		s = remaining
+
+		acceptableMediaType.Parameters[key] = value
+	}
+
+	extensionParameters := make(Parameters)
+	for len(s) > 0 && s[0] == ';' {
+		s = s[1:] // skip the semicolon
+
+		if p.lenient && p.emptyParameter(mediaTypeCount, s) {
+			s = skipWhiteSpaces(s)
+			continue
 		}
 
-		for i := 0; i < len(availableMediaTypes); i++ {
-			if compareMediaTypes(acceptableMediaType, availableMediaTypes[i]) &&
-				getPrecedence(acceptableMediaType, weights[i].mediaType) {
-				weights[i].mediaType = acceptableMediaType
-				weights[i].extensionParameters = extensionParameters
-				weights[i].weight = weight
-				weights[i].order = mediaTypeCount
-			}
+		key, value, remaining, consumed := consumeParameter(s)
+		if !consumed {
+			return mediaRange{}, remaining, p.fail(mediaTypeCount, remaining, expected("an extension parameter", remaining), ErrInvalidParameter)
 		}
 
-		s = skipWhiteSpaces(s)
+		s = remaining
+
+		extensionParameters[key] = value
 	}
 
-	// there must not be anything left after parsing the header
-	if len(s) > 0 {
-		return MediaType{}, Parameters{}, ErrInvalidMediaRange
+	return mediaRange{
+		mediaType:           acceptableMediaType,
+		extensionParameters: extensionParameters,
+		weight:              weight,
+		order:               mediaTypeCount,
+	}, s, nil
+}
+
+// Chooses the most suitable of the available media types for the parsed media ranges.
+func chooseMediaType(ranges []mediaRange, availableMediaTypes []MediaType) (MediaType, Parameters, error) {
+	weights := make([]mediaRange, len(availableMediaTypes))
+
+	for _, acceptable := range ranges {
+		for i := 0; i < len(availableMediaTypes); i++ {
+			if compareMediaTypes(acceptable.mediaType, availableMediaTypes[i]) &&
+				getPrecedence(acceptable.mediaType, weights[i].mediaType) {
+				weights[i] = acceptable
+			}
+		}
 	}
 
 	resultIndex := -1
@@ -474,3 +554,24 @@ func MatchAcceptableMediaType(request *http.Request, availableMediaTypes []Media
 
 	return availableMediaTypes[resultIndex], weights[resultIndex].extensionParameters, nil
 }
+
+// Chooses a media type from available media types according to the Accept.
+// Returns the most suitable media type or an error if no type can be selected.
+func MatchAcceptableMediaType(request *http.Request, availableMediaTypes []MediaType) (MediaType, Parameters, error) {
+	// RFC 7231, 5.3.2. Accept
+	if len(availableMediaTypes) == 0 {
+		return MediaType{}, Parameters{}, ErrNoAvailableTypeGiven
+	}
+
+	acceptHeaders := request.Header.Values("Accept")
+	if len(acceptHeaders) == 0 {
+		return availableMediaTypes[0], Parameters{}, nil
+	}
+
+	ranges, err := newParser("Accept", acceptHeaders[0], false).parseAccept()
+	if err != nil {
+		return MediaType{}, Parameters{}, err
+	}
+
+	return chooseMediaType(ranges, availableMediaTypes)
+}
@@ -35,6 +35,49 @@ func TestNewMediaType(t *testing.T) {
 	}
 }
 
+func TestParseMediaTypeString(t *testing.T) {
+	testCases := []struct {
+		name   string
+		value  string
+		result MediaType
+		err    error
+	}{
+		{"Type and subtype", "application/json", MediaType{"application", "json", Parameters{}}, nil},
+		{"Type, subtype, parameter", "A/B; C=\"D\"", MediaType{"a", "b", Parameters{"c": "d"}}, nil},
+		{"Quoted profile", "A/B; Profile=\"https://example.com/A\"", MediaType{"a", "b", Parameters{"profile": "https://example.com/A"}}, nil},
+		{"Empty string", "", MediaType{}, ErrInvalidMediaType},
+		{"Trailing characters", "text/plain junk", MediaType{}, ErrInvalidMediaType},
+		{"Invalid parameter", "a/b;c", MediaType{}, ErrInvalidParameter},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := ParseMediaTypeString(testCase.value)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("Unexpected error \"%v\", expected \"%v\" for %s", err, testCase.err, testCase.value)
+			} else if !reflect.DeepEqual(result, testCase.result) {
+				t.Fatalf("Invalid media type, got %v, expected %v for %s", result, testCase.result, testCase.value)
+			}
+			if err == nil && !reflect.DeepEqual(NewMediaType(testCase.value), result) {
+				t.Fatalf("NewMediaType and ParseMediaTypeString disagree for %s", testCase.value)
+			}
+		})
+	}
+}
+
+func TestMustMediaType(t *testing.T) {
+	if result := MustMediaType("text/plain;charset=utf-8"); result.Base() != "text/plain" || result.Parameters["charset"] != "utf-8" {
+		t.Errorf("Invalid media type, got %v", result)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for an invalid media type")
+		}
+	}()
+	MustMediaType("text/plain junk")
+}
+
 func TestString(t *testing.T) {
 	testCases := []struct {
 		name   string
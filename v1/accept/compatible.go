@@ -0,0 +1,37 @@
+package accept
+
+import (
+	"net/http"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// Chooses the media type to respond with for content of the given type
+// according to the Accept header of the request: the type itself or, if the
+// request does not accept it, the most acceptable of its fallbacks, as
+// determined by mime.Fallbacks. A request without an Accept header is given
+// the type itself.
+func MatchCompatibleMediaType(request *http.Request, t mime.Type) (MediaType, Parameters, error) {
+	availableMediaTypes, err := compatibleMediaTypes(t)
+	if err != nil {
+		return MediaType{}, Parameters{}, err
+	}
+	return MatchAcceptableMediaType(request, availableMediaTypes)
+}
+
+// Returns the type and its fallbacks as media types, the type first.
+func compatibleMediaTypes(t mime.Type) ([]MediaType, error) {
+	mediaType, err := FromType(t)
+	if err != nil {
+		return nil, err
+	}
+	availableMediaTypes := []MediaType{mediaType}
+	for _, e := range mime.Fallbacks(t) {
+		fallback, err := FromType(e)
+		if err != nil {
+			return nil, err
+		}
+		availableMediaTypes = append(availableMediaTypes, fallback)
+	}
+	return availableMediaTypes, nil
+}
@@ -0,0 +1,48 @@
+package accept
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestMatchCompatibleMediaType(t *testing.T) {
+	testCases := []struct {
+		name   string
+		t      mime.Type
+		accept string
+		result mime.Type
+		err    error
+	}{
+		{"No header", "application/vnd.acme+json", "", "application/vnd.acme+json", nil},
+		{"Any", "application/vnd.acme+json", "*/*", "application/vnd.acme+json", nil},
+		{"Type", "application/vnd.acme+json", "application/vnd.acme+json", "application/vnd.acme+json", nil},
+		{"Suffix type", "application/vnd.acme+json", "application/json", "application/json", nil},
+		{"Text", "application/vnd.acme+json", "text/plain", "text/plain", nil},
+		{"Most acceptable", "application/vnd.acme+json", "text/plain;q=0.5, application/json;q=0.8", "application/json", nil},
+		{"Markdown", mime.Markdown, "text/html, text/plain;q=0.9", "text/plain", nil},
+		{"Parameters", "text/markdown;charset=utf-8", "text/*", "text/markdown;charset=utf-8", nil},
+		{"XHTML", "application/xhtml+xml", "application/xml", "application/xml", nil},
+		{"Not acceptable", mime.Markdown, "text/html", mime.Invalid, ErrNoAcceptableTypeFound},
+		{"No fallbacks", "image/png", "text/plain", mime.Invalid, ErrNoAcceptableTypeFound},
+		{"Invalid", mime.Invalid, "*/*", mime.Invalid, ErrInvalidMediaType},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "http://test.test/", nil)
+			if testCase.accept != "" {
+				request.Header.Set("Accept", testCase.accept)
+			}
+			result, _, err := MatchCompatibleMediaType(request, testCase.t)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			if result.Mime() != testCase.result {
+				t.Errorf("Unexpected media type %q, expected %q", result.Mime(), testCase.result)
+			}
+		})
+	}
+}
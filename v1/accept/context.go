@@ -0,0 +1,31 @@
+package accept
+
+import (
+	"context"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+type mediaTypeContextKey struct{}
+
+// Returns a copy of the context carrying the media type chosen for a response.
+func WithMediaType(ctx context.Context, mediaType MediaType) context.Context {
+	return context.WithValue(ctx, mediaTypeContextKey{}, mediaType)
+}
+
+// Returns the media type chosen for a response by one of the handlers in this
+// package, if there is one.
+func MediaTypeFromContext(ctx context.Context) (MediaType, bool) {
+	mediaType, ok := ctx.Value(mediaTypeContextKey{}).(MediaType)
+	return mediaType, ok
+}
+
+// Returns the media type chosen for a response by one of the handlers in this
+// package as a mime.Type, if there is one.
+func TypeFromContext(ctx context.Context) (mime.Type, bool) {
+	mediaType, ok := MediaTypeFromContext(ctx)
+	if !ok {
+		return mime.Invalid, false
+	}
+	return mediaType.Mime(), true
+}
@@ -0,0 +1,57 @@
+package accept
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A ParseError describes where and why a header or media type string could
+// not be parsed. It wraps one of the package's errors, like ErrInvalidParameter,
+// so callers can still test for those with errors.Is.
+type ParseError struct {
+	Header  string // name of the header, or empty when a plain string was parsed
+	Input   string // the value which was parsed
+	Offset  int    // byte offset into Input at which the problem was found
+	Element int    // index of the comma-separated element containing the problem
+	Message string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.Header != "" {
+		b.WriteString(e.Header)
+		b.WriteString(": ")
+	}
+	fmt.Fprintf(&b, "%v at offset %d (element %d)", e.Err, e.Offset, e.Element)
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Creates an error for the problem found at the start of the remaining input.
+func (p *parser) fail(element int, remaining, message string, err error) *ParseError {
+	return &ParseError{
+		Header:  p.header,
+		Input:   p.input,
+		Offset:  len(p.input) - len(remaining),
+		Element: element,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// Describes what was expected and what was found at the start of the
+// remaining input instead.
+func expected(what, remaining string) string {
+	if len(remaining) == 0 {
+		return fmt.Sprintf("expected %s, found end of input", what)
+	}
+	return fmt.Sprintf("expected %s, found %q", what, remaining[0])
+}
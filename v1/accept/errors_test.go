@@ -0,0 +1,76 @@
+package accept
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		header  string
+		value   string
+		err     error
+		offset  int
+		element int
+	}{
+		{"Content-Type without subtype", "Content-Type", "text", ErrInvalidMediaType, 4, 0},
+		{"Content-Type invalid parameter", "Content-Type", "text/plain; charset", ErrInvalidParameter, 19, 0},
+		{"Content-Type trailing characters", "Content-Type", "text/plain junk", ErrInvalidMediaType, 11, 0},
+		{"Accept invalid weight", "Accept", "a/b, c/d;q=2", ErrInvalidWeight, 9, 1},
+		{"Accept invalid parameter", "Accept", "a/b, c/d, e/f;=g", ErrInvalidParameter, 14, 2},
+		{"Accept trailing characters", "Accept", "a/b c", ErrInvalidMediaRange, 4, 0},
+		{"Accept trailing characters in later element", "Accept", "a/b, c/d e, f/g", ErrInvalidMediaRange, 9, 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			request.Header.Set(testCase.header, testCase.value)
+
+			if testCase.header == "Accept" {
+				_, _, err = MatchAcceptableMediaType(request, []MediaType{{"a", "b", Parameters{}}})
+			} else {
+				_, err = ParseMediaType(request)
+			}
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a parse error for %s, got %v", testCase.value, err)
+			}
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%s\", expected \"%v\" for %s", err, testCase.err, testCase.value)
+			}
+			if parseErr.Header != testCase.header || parseErr.Input != testCase.value {
+				t.Errorf("Wrong header or input, got %s: %s, expected %s: %s", parseErr.Header, parseErr.Input, testCase.header, testCase.value)
+			}
+			if parseErr.Offset != testCase.offset || parseErr.Element != testCase.element {
+				t.Errorf("Wrong position, got offset %d, element %d, expected offset %d, element %d for %s", parseErr.Offset, parseErr.Element, testCase.offset, testCase.element, testCase.value)
+			}
+			if parseErr.Message == "" {
+				t.Errorf("Expected a message for %s", testCase.value)
+			}
+		})
+	}
+}
+
+func TestParseErrorString(t *testing.T) {
+	testCases := []struct {
+		err    *ParseError
+		result string
+	}{
+		{&ParseError{Header: "Accept", Input: "a/b c", Offset: 4, Element: 0, Message: "expected a comma", Err: ErrInvalidMediaRange}, "Accept: invalid media range at offset 4 (element 0): expected a comma"},
+		{&ParseError{Input: "a/b, c", Offset: 6, Element: 1, Err: ErrInvalidMediaType}, "invalid media type at offset 6 (element 1)"},
+	}
+
+	for _, testCase := range testCases {
+		if result := testCase.err.Error(); result != testCase.result {
+			t.Errorf("Unexpected message %q, expected %q", result, testCase.result)
+		}
+	}
+}
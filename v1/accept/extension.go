@@ -0,0 +1,169 @@
+package accept
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// Format named by the query parameter of an ExtensionHandler is neither a
+// known file extension nor a media type.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// The query parameter conventionally used to name a format, as in
+// /report?format=csv.
+const DefaultFormatParameter = "format"
+
+// An ExtensionHandler lets clients which cannot set the Accept header choose
+// a media type with a file extension in the path of the request, like
+// /report.csv, or optionally with a query parameter, like /report?format=csv.
+// The chosen type overrides the Accept header; the extension is removed from
+// the path before the request is passed on, so the wrapped handler serves
+// /report for every format.
+//
+// Extensions are mapped to types with the reverse of mime.Type.Ext. Only
+// extensions which map to one of the available media types are recognized,
+// so a path like /photos/cat.png is passed on as it is when image/png is not
+// available.
+type ExtensionHandler struct {
+	availableMediaTypes []MediaType
+	handler             http.Handler
+	// The query parameter which names a format, either as an extension, like
+	// csv, or as a media type, like text/csv. If it is empty, formats are not
+	// read from the query. A format in the query takes precedence over an
+	// extension in the path. Since a plus sign in a query is decoded as a
+	// space, a space in a media type is read as a plus sign, so that
+	// ?format=application/vnd.api+json works as well as the escaped
+	// ?format=application/vnd.api%2Bjson.
+	FormatParameter string
+	// Maps extensions to types. If it is nil mime.DefaultResolver is used.
+	Resolver *mime.Resolver
+	// Handles requests for which no available media type is acceptable. If it
+	// is nil a 406 Not Acceptable response is written.
+	NotAcceptable http.Handler
+}
+
+// Creates an ExtensionHandler which passes requests to the handler, choosing
+// from the available types. Requests which name no format are negotiated
+// with their Accept header, and a request without an Accept header is given
+// the first available type. It panics if a type is invalid.
+func NewExtensionHandler(available []mime.Type, handler http.Handler) *ExtensionHandler {
+	availableMediaTypes := make([]MediaType, 0, len(available))
+	for _, t := range available {
+		mediaType, err := FromType(t)
+		if err != nil {
+			panic(fmt.Sprintf("accept: %q is not a valid media type: %v", t, err))
+		}
+		availableMediaTypes = append(availableMediaTypes, mediaType)
+	}
+	return &ExtensionHandler{availableMediaTypes: availableMediaTypes, handler: handler}
+}
+
+func (h *ExtensionHandler) resolver() *mime.Resolver {
+	if h.Resolver != nil {
+		return h.Resolver
+	}
+	return mime.DefaultResolver
+}
+
+// Returns the first available media type which is one of the types.
+func (h *ExtensionHandler) choose(types mime.Options) (MediaType, bool) {
+	for _, mediaType := range h.availableMediaTypes {
+		if types.Match(mediaType.Mime()) {
+			return mediaType, true
+		}
+	}
+	return MediaType{}, false
+}
+
+// Returns the types a format in the query names.
+func (h *ExtensionHandler) formatTypes(format string) (mime.Options, error) {
+	if strings.Contains(format, "/") {
+		mediaType, err := ParseMediaTypeString(strings.ReplaceAll(format, " ", "+"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+		}
+		return mime.Options{mediaType.Mime()}, nil
+	}
+	if types := h.resolver().TypesByExtension(format); len(types) > 0 {
+		return types, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Chooses the media type for the request and returns the path of the request
+// with any recognized extension removed. The format in the query, the
+// extension in the path and the Accept header are considered in that order.
+func (h *ExtensionHandler) Match(request *http.Request) (MediaType, string, error) {
+	if len(h.availableMediaTypes) == 0 {
+		return MediaType{}, request.URL.Path, ErrNoAvailableTypeGiven
+	}
+
+	var (
+		result MediaType
+		found  bool
+		p      = request.URL.Path
+	)
+	if ext := path.Ext(p); len(ext) > 1 && !strings.HasSuffix(p, "/"+ext) {
+		if mediaType, ok := h.choose(h.resolver().TypesByExtension(ext)); ok {
+			result, found = mediaType, true
+			p = strings.TrimSuffix(p, ext)
+		}
+	}
+
+	if h.FormatParameter != "" {
+		if format := request.URL.Query().Get(h.FormatParameter); format != "" {
+			types, err := h.formatTypes(format)
+			if err != nil {
+				return MediaType{}, p, err
+			}
+			mediaType, ok := h.choose(types)
+			if !ok {
+				return MediaType{}, p, ErrNoAcceptableTypeFound
+			}
+			return mediaType, p, nil
+		}
+	}
+
+	if found {
+		return result, p, nil
+	}
+	mediaType, _, err := MatchAcceptableMediaType(request, h.availableMediaTypes)
+	return mediaType, p, err
+}
+
+// Passes the request to the wrapped handler with the extension removed from
+// its path. The handler can retrieve the chosen media type with
+// MediaTypeFromContext or TypeFromContext. Invalid Accept headers and unknown
+// formats produce a 400 Bad Request response.
+func (h *ExtensionHandler) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	w.Header().Add("Vary", "Accept")
+
+	mediaType, p, err := h.Match(request)
+	var parseErr *ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		http.Error(w, parseErr.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrUnknownFormat):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil && h.NotAcceptable != nil:
+		h.NotAcceptable.ServeHTTP(w, request)
+	case err != nil:
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+	default:
+		request = request.WithContext(WithMediaType(request.Context(), mediaType))
+		if p != request.URL.Path {
+			// A raw path whose extension is itself escaped keeps it, and is
+			// then ignored by EscapedPath since it no longer encodes the path.
+			u := *request.URL
+			u.RawPath = strings.TrimSuffix(u.RawPath, u.Path[len(p):])
+			u.Path = p
+			request.URL = &u
+		}
+		h.handler.ServeHTTP(w, request)
+	}
+}
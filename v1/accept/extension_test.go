@@ -0,0 +1,121 @@
+package accept
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func newExtensionHandler() *ExtensionHandler {
+	handler := NewExtensionHandler([]mime.Type{mime.JSON, mime.CSV, "text/xml", "application/vnd.api+json"}, http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		t, _ := TypeFromContext(request.Context())
+		io.WriteString(w, request.URL.EscapedPath()+" "+t.String())
+	}))
+	handler.FormatParameter = DefaultFormatParameter
+	return handler
+}
+
+func TestExtensionHandler(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target string
+		header string
+		status int
+		result string
+	}{
+		{"No extension", "/report", "", http.StatusOK, "/report application/json"},
+		{"Accept header", "/report", "text/csv", http.StatusOK, "/report text/csv"},
+		{"Extension", "/report.csv", "", http.StatusOK, "/report text/csv"},
+		{"Extension case", "/report.CSV", "", http.StatusOK, "/report text/csv"},
+		{"Extension overrides header", "/report.csv", "application/json", http.StatusOK, "/report text/csv"},
+		{"Extension with several types", "/report.xml", "", http.StatusOK, "/report text/xml"},
+		{"Nested path", "/orders/2024.json", "", http.StatusOK, "/orders/2024 application/json"},
+		{"Escaped path", "/a%2Fb.csv", "", http.StatusOK, "/a%2Fb text/csv"},
+		{"Escaped extension", "/a%2Fb.c%73v", "", http.StatusOK, "/a/b text/csv"},
+		{"Unavailable extension", "/photos/cat.png", "", http.StatusOK, "/photos/cat.png application/json"},
+		{"Unknown extension", "/v1.2", "text/csv", http.StatusOK, "/v1.2 text/csv"},
+		{"Extension only", "/.json", "text/csv", http.StatusOK, "/.json text/csv"},
+		{"Directory", "/reports.csv/", "", http.StatusOK, "/reports.csv/ application/json"},
+		{"Format", "/report?format=csv", "application/json", http.StatusOK, "/report text/csv"},
+		{"Format with dot", "/report?format=.csv", "", http.StatusOK, "/report text/csv"},
+		{"Format media type", "/report?format=text/csv", "", http.StatusOK, "/report text/csv"},
+		{"Format with suffix", "/report?format=application/vnd.api%2Bjson", "", http.StatusOK, "/report application/vnd.api+json"},
+		{"Format with unescaped suffix", "/report?format=application/vnd.api+json", "", http.StatusOK, "/report application/vnd.api+json"},
+		{"Format overrides extension", "/report.json?format=csv", "", http.StatusOK, "/report text/csv"},
+		{"Empty format", "/report.csv?format=", "", http.StatusOK, "/report text/csv"},
+		{"Unavailable format", "/report?format=png", "", http.StatusNotAcceptable, ""},
+		{"Unknown format", "/report?format=nope", "", http.StatusBadRequest, ""},
+		{"Not acceptable", "/report", "image/png", http.StatusNotAcceptable, ""},
+		{"Invalid header", "/report", "application/json;q=2", http.StatusBadRequest, ""},
+	}
+
+	handler := newExtensionHandler()
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, "http://test.test"+testCase.target, nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if len(testCase.header) > 0 {
+				request.Header.Set("Accept", testCase.header)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != testCase.status {
+				t.Errorf("Unexpected status %d, expected %d for %s", recorder.Code, testCase.status, testCase.target)
+			} else if testCase.status == http.StatusOK && recorder.Body.String() != testCase.result {
+				t.Errorf("Unexpected result %q, expected %q for %s", recorder.Body.String(), testCase.result, testCase.target)
+			}
+			if vary := recorder.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("Missing Vary header, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestExtensionHandlerWithoutFormat(t *testing.T) {
+	handler := newExtensionHandler()
+	handler.FormatParameter = ""
+	handler.NotAcceptable = http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/report.json?format=csv", nil)
+	mediaType, p, err := handler.Match(request)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%v\"", err)
+	}
+	if mediaType.Mime() != mime.JSON || p != "/report" {
+		t.Errorf("Unexpected match %q, %q", mediaType.Mime(), p)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/report", nil)
+	request.Header.Set("Accept", "image/png")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusGone {
+		t.Errorf("Unexpected status %d, expected %d", recorder.Code, http.StatusGone)
+	}
+
+	empty := NewExtensionHandler(nil, http.NotFoundHandler())
+	if _, _, err := empty.Match(request); !errors.Is(err, ErrNoAvailableTypeGiven) {
+		t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, ErrNoAvailableTypeGiven)
+	}
+}
+
+func TestTypeFromContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, ok := TypeFromContext(request.Context()); ok {
+		t.Errorf("Expected no type in the context")
+	}
+	ctx := WithMediaType(request.Context(), CSV.With("header", "present"))
+	if result, ok := TypeFromContext(ctx); !ok || result != "text/csv;header=present" {
+		t.Errorf("Unexpected type %q, expected %q", result, "text/csv;header=present")
+	}
+}
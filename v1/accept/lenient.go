@@ -0,0 +1,143 @@
+package accept
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// A recoverable problem found while parsing a header leniently. Err is the
+// error strict parsing would have reported for the same problem.
+type Warning struct {
+	Offset  int // byte offset into the header value
+	Element int // index of the comma-separated element in the header
+	Message string
+	Err     error
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s at offset %d, element %d: %v", w.Message, w.Offset, w.Element, w.Err)
+}
+
+// Parser state shared by the strict and lenient parsing modes.
+type parser struct {
+	header   string
+	input    string
+	lenient  bool
+	warnings []Warning
+}
+
+func newParser(header, input string, lenient bool) *parser {
+	return &parser{header: header, input: input, lenient: lenient}
+}
+
+// Records a warning for the problem found at the start of the remaining input.
+// When the error is a ParseError the warning takes its position and message
+// from it, and its error is the one the ParseError wraps.
+func (p *parser) warn(element int, remaining, message string, err error) {
+	w := Warning{
+		Offset:  len(p.input) - len(remaining),
+		Element: element,
+		Message: message,
+		Err:     err,
+	}
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		w.Offset, w.Element, w.Err = parseErr.Offset, parseErr.Element, parseErr.Err
+		if parseErr.Message != "" {
+			w.Message += ": " + parseErr.Message
+		}
+	}
+	p.warnings = append(p.warnings, w)
+}
+
+// Reports whether the parameter following a semicolon is missing entirely,
+// as in "a/b;" or "a/b;;c=d", recording a warning if so.
+func (p *parser) emptyParameter(element int, s string) bool {
+	remaining := skipWhiteSpaces(s)
+	if len(remaining) > 0 && remaining[0] != ';' && remaining[0] != ',' {
+		return false
+	}
+	p.warn(element, s, "skipped empty parameter", ErrInvalidParameter)
+	return true
+}
+
+// Consumes a lone "*", which some old clients send in place of "*/*".
+func consumeBareWildcard(s string) (string, bool) {
+	s = skipWhiteSpaces(s)
+	if len(s) == 0 || s[0] != '*' {
+		return s, false
+	}
+	remaining := skipWhiteSpaces(s[1:])
+	if len(remaining) > 0 && remaining[0] != ';' && remaining[0] != ',' {
+		return s, false
+	}
+	return remaining, true
+}
+
+// Skips to the first of the separators which is not inside a quoted string.
+func skipUntil(s string, separators string) string {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++ // skip the quoted pair
+		case c == '"':
+			quoted = !quoted
+		case !quoted:
+			for j := 0; j < len(separators); j++ {
+				if c == separators[j] {
+					return s[i:]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func skipParameter(s string) string {
+	return skipUntil(s, ";")
+}
+
+func skipElement(s string) string {
+	return skipUntil(s, ",")
+}
+
+// Like ParseMediaType, but invalid parameters, empty parameters and trailing
+// characters are skipped and reported as warnings instead of failing. The
+// media type itself must still be valid.
+func ParseMediaTypeLenient(request *http.Request) (MediaType, []Warning, error) {
+	contentTypeHeaders := request.Header.Values("Content-Type")
+	if len(contentTypeHeaders) == 0 {
+		return MediaType{}, nil, nil
+	}
+
+	p := newParser("Content-Type", contentTypeHeaders[0], true)
+	mediaType, err := p.parseMediaType()
+	return mediaType, p.warnings, err
+}
+
+// Like MatchAcceptableMediaType, but tolerates the malformed Accept headers
+// real clients send. Invalid media ranges and empty elements are skipped, a
+// lone "*" is read as "*/*", weights like "q=.5" are accepted and trailing
+// semicolons are ignored. Each of these is reported as a warning; an error is
+// only returned when no media type can be selected.
+func MatchAcceptableMediaTypeLenient(request *http.Request, availableMediaTypes []MediaType) (MediaType, Parameters, []Warning, error) {
+	if len(availableMediaTypes) == 0 {
+		return MediaType{}, Parameters{}, nil, ErrNoAvailableTypeGiven
+	}
+
+	acceptHeaders := request.Header.Values("Accept")
+	if len(acceptHeaders) == 0 {
+		return availableMediaTypes[0], Parameters{}, nil, nil
+	}
+
+	p := newParser("Accept", acceptHeaders[0], true)
+	ranges, err := p.parseAccept()
+	if err != nil {
+		return MediaType{}, Parameters{}, p.warnings, err
+	}
+
+	mediaType, extensionParameters, err := chooseMediaType(ranges, availableMediaTypes)
+	return mediaType, extensionParameters, p.warnings, err
+}
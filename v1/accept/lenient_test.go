@@ -0,0 +1,141 @@
+package accept
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMatchAcceptableMediaTypeLenient(t *testing.T) {
+	testCases := []struct {
+		name                string
+		header              string
+		availableMediaTypes []MediaType
+		result              MediaType
+		warnings            []error
+	}{
+		{"Valid header", "a/b", []MediaType{
+			{"a", "b", Parameters{}},
+		}, MediaType{"a", "b", Parameters{}}, nil},
+		{"Old Java header", "text/html, image/gif, image/jpeg, *; q=.2, */*; q=.2", []MediaType{
+			{"application", "json", Parameters{}},
+		}, MediaType{"application", "json", Parameters{}}, []error{ErrInvalidMediaType, ErrInvalidWeight, ErrInvalidWeight}},
+		{"Bare wildcard", "*", []MediaType{
+			{"a", "b", Parameters{}},
+		}, MediaType{"a", "b", Parameters{}}, []error{ErrInvalidMediaType}},
+		{"Invalid range skipped", "a/b;c,c/d", []MediaType{
+			{"a", "b", Parameters{}},
+			{"c", "d", Parameters{}},
+		}, MediaType{"c", "d", Parameters{}}, []error{ErrInvalidParameter}},
+		{"Trailing garbage skipped", "a/b junk,c/d", []MediaType{
+			{"a", "b", Parameters{}},
+			{"c", "d", Parameters{}},
+		}, MediaType{"c", "d", Parameters{}}, []error{ErrInvalidMediaRange}},
+		{"Trailing semicolon", "a/b;", []MediaType{
+			{"a", "b", Parameters{}},
+		}, MediaType{"a", "b", Parameters{}}, []error{ErrInvalidParameter}},
+		{"Double semicolon", "a/b;;q=0.5, c/d;q=0.4", []MediaType{
+			{"c", "d", Parameters{}},
+			{"a", "b", Parameters{}},
+		}, MediaType{"a", "b", Parameters{}}, []error{ErrInvalidParameter}},
+		{"Empty elements", ", ,a/b,", []MediaType{
+			{"a", "b", Parameters{}},
+		}, MediaType{"a", "b", Parameters{}}, []error{ErrInvalidMediaType, ErrInvalidMediaType, ErrInvalidMediaType}},
+		{"Comma in quoted string of skipped range", "a/b;c=\"x,y\" z,c/d", []MediaType{
+			{"a", "b", Parameters{}},
+			{"c", "d", Parameters{}},
+		}, MediaType{"c", "d", Parameters{}}, []error{ErrInvalidMediaRange}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if len(testCase.header) > 0 {
+				request.Header.Set("Accept", testCase.header)
+			}
+
+			result, _, warnings, err := MatchAcceptableMediaTypeLenient(request, testCase.availableMediaTypes)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\" for %s", err, testCase.header)
+			} else if result.Type != testCase.result.Type || result.Subtype != testCase.result.Subtype {
+				t.Errorf("Invalid content type, got %s/%s, exptected %s/%s for %s", result.Type, result.Subtype, testCase.result.Type, testCase.result.Subtype, testCase.header)
+			} else if len(warnings) != len(testCase.warnings) {
+				t.Errorf("Wrong warnings, got %v, expected %v for %s", warnings, testCase.warnings, testCase.header)
+			} else {
+				for i, warning := range warnings {
+					if !errors.Is(warning.Err, testCase.warnings[i]) {
+						t.Errorf("Wrong warning #%d, got %v, expected %v for %s", i, warning, testCase.warnings[i], testCase.header)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMatchAcceptableMediaTypeLenientErrors(t *testing.T) {
+	request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	request.Header.Set("Accept", "a/b;q=2, junk")
+
+	_, _, warnings, err := MatchAcceptableMediaTypeLenient(request, []MediaType{{"a", "b", Parameters{}}})
+	if !errors.Is(err, ErrNoAcceptableTypeFound) {
+		t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, ErrNoAcceptableTypeFound)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Wrong warnings, got %v, expected two", warnings)
+	}
+	if warnings[0].Offset != 4 || warnings[0].Element != 0 {
+		t.Errorf("Wrong position for %v, expected offset 4, element 0", warnings[0])
+	}
+	if warnings[1].Offset != 13 || warnings[1].Element != 1 {
+		t.Errorf("Wrong position for %v, expected offset 13, element 1", warnings[1])
+	}
+	expect := "skipped invalid media range: expected a type and subtype, found end of input at offset 13, element 1: invalid media type"
+	if warnings[1].String() != expect {
+		t.Errorf("Unexpected warning %q, expected %q", warnings[1].String(), expect)
+	}
+	if warnings[1].Err != ErrInvalidMediaType {
+		t.Errorf("Unexpected error \"%v\", expected \"%v\"", warnings[1].Err, ErrInvalidMediaType)
+	}
+}
+
+func TestParseMediaTypeLenient(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		result   MediaType
+		warnings int
+	}{
+		{"Valid header", "a/b;c=d", MediaType{"a", "b", Parameters{"c": "d"}}, 0},
+		{"Trailing semicolon", "text/html; charset=utf-8;", MediaType{"text", "html", Parameters{"charset": "utf-8"}}, 1},
+		{"Invalid parameter", "a/b;c;d=e", MediaType{"a", "b", Parameters{"d": "e"}}, 1},
+		{"Trailing characters", "a/b junk", MediaType{"a", "b", Parameters{}}, 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			request.Header.Set("Content-Type", testCase.header)
+
+			result, warnings, err := ParseMediaTypeLenient(request)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\" for %s", err, testCase.header)
+			} else if !reflect.DeepEqual(result, testCase.result) {
+				t.Errorf("Invalid content type, got %v, expected %v for %s", result, testCase.result, testCase.header)
+			} else if len(warnings) != testCase.warnings {
+				t.Errorf("Wrong warnings, got %v, expected %d for %s", warnings, testCase.warnings, testCase.header)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package accept
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Link in the Link header is syntactically invalid.
+var ErrInvalidLink = errors.New("invalid link")
+
+// A web link from a Link header: a target URI and the parameters describing
+// its relation to the current resource.
+type Link struct {
+	URI        string
+	Parameters Parameters
+}
+
+// Creates a link to a profile the current resource conforms to.
+func ProfileLink(uri string) Link {
+	// RFC 6906, 2. Profiles
+	return Link{URI: uri, Parameters: Parameters{"rel": "profile"}}
+}
+
+// Creates a link to an alternate representation of the current resource
+// with the given media type.
+func AlternateLink(uri string, mediaType MediaType) Link {
+	return Link{URI: uri, Parameters: Parameters{"rel": "alternate", "type": mediaType.String()}}
+}
+
+// Returns the relation types of the link.
+func (link Link) Rels() []string {
+	return strings.Fields(strings.ToLower(link.Parameters["rel"]))
+}
+
+// Reports whether the link has the relation type.
+func (link Link) HasRel(rel string) bool {
+	for _, e := range link.Rels() {
+		if e == strings.ToLower(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the media type hint of the link, if it has one.
+func (link Link) MediaType() (MediaType, bool) {
+	mediaType, err := ParseMediaTypeString(link.Parameters["type"])
+	return mediaType, err == nil
+}
+
+// Converts the link to its Link header representation. The rel parameter is
+// written first and the others are sorted by name.
+func (link Link) String() string {
+	var stringBuilder strings.Builder
+	stringBuilder.WriteByte('<')
+	stringBuilder.WriteString(link.URI)
+	stringBuilder.WriteByte('>')
+
+	keys := make([]string, 0, len(link.Parameters))
+	for key := range link.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == "rel" || keys[j] == "rel" {
+			return keys[i] == "rel"
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		stringBuilder.WriteString("; ")
+		stringBuilder.WriteString(key)
+		if value := link.Parameters[key]; len(value) > 0 {
+			stringBuilder.WriteByte('=')
+			writeQuotedString(&stringBuilder, value)
+		}
+	}
+
+	return stringBuilder.String()
+}
+
+func writeQuotedString(stringBuilder *strings.Builder, s string) {
+	stringBuilder.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			stringBuilder.WriteByte('\\')
+		}
+		stringBuilder.WriteByte(s[i])
+	}
+	stringBuilder.WriteByte('"')
+}
+
+// Formats links as the value of a Link header.
+func FormatLinks(links []Link) string {
+	var stringBuilder strings.Builder
+	for i, link := range links {
+		if i > 0 {
+			stringBuilder.WriteString(", ")
+		}
+		stringBuilder.WriteString(link.String())
+	}
+	return stringBuilder.String()
+}
+
+// Parses the value of a Link header. Parameter names are converted to lower
+// case, but the case of URIs and parameter values is preserved.
+func ParseLinks(s string) ([]Link, error) {
+	// RFC 8288, 3. Link Serialisation in HTTP Headers
+	p := newParser("Link", s, false)
+	var links []Link
+
+	for count := 0; len(s) > 0; count++ {
+		if count > 0 {
+			if s[0] != ',' {
+				return nil, p.fail(count, s, expected("a comma", s), ErrInvalidLink)
+			}
+			s = s[1:] // skip the comma
+		}
+
+		uri, remaining, consumed := consumeURIReference(s)
+		if !consumed {
+			return nil, p.fail(count, remaining, expected("a URI in angle brackets", remaining), ErrInvalidLink)
+		}
+		s = remaining
+
+		link := Link{URI: uri, Parameters: make(Parameters)}
+		for len(s) > 0 && s[0] == ';' {
+			s = s[1:] // skip the semicolon
+
+			key, value, remaining, consumed := consumeLinkParameter(s)
+			if !consumed {
+				return nil, p.fail(count, remaining, expected("a link parameter", remaining), ErrInvalidParameter)
+			}
+			s = remaining
+
+			// RFC 8288, 3.3. only the first occurrence of rel is used
+			if _, found := link.Parameters[key]; !found || key != "rel" {
+				link.Parameters[key] = value
+			}
+		}
+
+		links = append(links, link)
+		s = skipWhiteSpaces(s)
+	}
+
+	return links, nil
+}
+
+func consumeLinkParameter(s string) (string, string, string, bool) {
+	// RFC 8288, 3. Link Serialisation in HTTP Headers
+	s = skipWhiteSpaces(s)
+
+	key, s, consumed := consumeToken(s)
+	if !consumed {
+		return "", "", s, false
+	}
+
+	s = skipWhiteSpaces(s)
+	if len(s) == 0 || s[0] != '=' {
+		return key, "", s, true // parameters without a value are allowed
+	}
+	s = skipWhiteSpaces(s[1:]) // skip the equal sign
+
+	var value string
+	if len(s) > 0 && s[0] == '"' {
+		value, s, consumed = consumeRawQuotedString(s[1:])
+		if !consumed || len(s) == 0 || s[0] != '"' {
+			return "", "", s, false
+		}
+		s = s[1:] // skip the closing quote
+	} else {
+		value, s, consumed = consumeRawToken(s)
+		if !consumed {
+			return "", "", s, false
+		}
+	}
+
+	return key, value, skipWhiteSpaces(s), true
+}
+
+// Gets the links from every Link header of the request.
+func ParseLinkHeader(header http.Header) ([]Link, error) {
+	var links []Link
+	for _, value := range header.Values("Link") {
+		parsed, err := ParseLinks(value)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, parsed...)
+	}
+	return links, nil
+}
+
+// Returns the URIs of the links with the profile relation type.
+func ProfileLinks(links []Link) []string {
+	var profiles []string
+	for _, link := range links {
+		if link.HasRel("profile") {
+			profiles = append(profiles, link.URI)
+		}
+	}
+	return profiles
+}
+
+// Returns the links to alternate representations, along with their media
+// types. Alternates without a valid type hint are skipped.
+func AlternateLinks(links []Link) ([]Link, []MediaType) {
+	var alternates []Link
+	var mediaTypes []MediaType
+	for _, link := range links {
+		if !link.HasRel("alternate") {
+			continue
+		}
+		if mediaType, ok := link.MediaType(); ok {
+			alternates = append(alternates, link)
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	return alternates, mediaTypes
+}
@@ -0,0 +1,102 @@
+package accept
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		result []Link
+	}{
+		{"Empty header", "", nil},
+		{"Profile", `<https://example.com/Profile>; rel="profile"`, []Link{
+			{"https://example.com/Profile", Parameters{"rel": "profile"}},
+		}},
+		{"Multiple links", `<https://example.com/a.json>; rel=alternate; type="application/json", </b.csv>;rel="alternate";type="text/csv"`, []Link{
+			{"https://example.com/a.json", Parameters{"rel": "alternate", "type": "application/json"}},
+			{"/b.csv", Parameters{"rel": "alternate", "type": "text/csv"}},
+		}},
+		{"Case and escapes preserved", `<Next>; REL=next; title="A \"Title\""`, []Link{
+			{"Next", Parameters{"rel": "next", "title": `A "Title"`}},
+		}},
+		{"Parameter without value", `<a>; rel=preload; crossorigin`, []Link{
+			{"a", Parameters{"rel": "preload", "crossorigin": ""}},
+		}},
+		{"First rel wins", `<a>; rel=first; rel=second`, []Link{
+			{"a", Parameters{"rel": "first"}},
+		}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := ParseLinks(testCase.header)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\" for %s", err, testCase.header)
+			} else if !reflect.DeepEqual(result, testCase.result) {
+				t.Errorf("Invalid links, got %v, expected %v for %s", result, testCase.result, testCase.header)
+			}
+		})
+	}
+}
+
+func TestParseLinksErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		err    error
+	}{
+		{"Missing brackets", "https://example.com", ErrInvalidLink},
+		{"Unterminated URI", "<https://example.com", ErrInvalidLink},
+		{"Space in URI", "<https://example.com/a b>", ErrInvalidLink},
+		{"Missing comma", "<a> <b>", ErrInvalidLink},
+		{"Unterminated quoted string", `<a>; rel="next`, ErrInvalidParameter},
+		{"Missing parameter name", "<a>; =next", ErrInvalidParameter},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParseLinks(testCase.header)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\" for %s", err, testCase.err, testCase.header)
+			}
+		})
+	}
+}
+
+func TestFormatLinks(t *testing.T) {
+	links := []Link{
+		ProfileLink("https://example.com/v2"),
+		AlternateLink("/orders.csv", CSV.With("header", "present")),
+		{"/a", Parameters{"title": `A "B"`, "anchor": "#x", "rel": "next", "crossorigin": ""}},
+	}
+
+	expected := `<https://example.com/v2>; rel="profile", ` +
+		`</orders.csv>; rel="alternate"; type="text/csv;header=present", ` +
+		`</a>; rel="next"; anchor="#x"; crossorigin; title="A \"B\""`
+	if result := FormatLinks(links); result != expected {
+		t.Errorf("Invalid links, got %s, expected %s", result, expected)
+	}
+
+	header := http.Header{}
+	header.Add("Link", FormatLinks(links[:2]))
+	header.Add("Link", links[2].String())
+	parsed, err := ParseLinkHeader(header)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	} else if !reflect.DeepEqual(parsed, links) {
+		t.Errorf("Invalid links, got %v, expected %v", parsed, links)
+	}
+
+	if profiles := ProfileLinks(parsed); !reflect.DeepEqual(profiles, []string{"https://example.com/v2"}) {
+		t.Errorf("Invalid profiles, got %v", profiles)
+	}
+	if alternates, mediaTypes := AlternateLinks(parsed); len(alternates) != 1 || alternates[0].URI != "/orders.csv" ||
+		!reflect.DeepEqual(mediaTypes, []MediaType{CSV.With("header", "present")}) {
+		t.Errorf("Invalid alternates, got %v, %v", alternates, mediaTypes)
+	}
+}
@@ -0,0 +1,139 @@
+package accept
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// The number of distinct Accept headers a Negotiator remembers when no
+// capacity is specified.
+const DefaultNegotiatorCapacity = 256
+
+// The memoized outcome of negotiating a single Accept header.
+type negotiation struct {
+	header              string
+	mediaType           MediaType
+	extensionParameters Parameters
+	err                 error
+}
+
+// A Negotiator choses media types from a fixed list of available media types
+// and remembers the outcome for the most recently seen Accept headers, so each
+// distinct header is only parsed once while it remains in the cache. Errors are
+// remembered along with successful results. A Negotiator is safe for concurrent
+// use.
+type Negotiator struct {
+	availableMediaTypes []MediaType
+	capacity            int
+	mu                  sync.Mutex
+	entries             map[string]*list.Element
+	order               *list.List
+	hits                atomic.Uint64
+	misses              atomic.Uint64
+}
+
+// Creates a Negotiator for the available media types which remembers up to
+// capacity distinct Accept headers. If capacity is not positive,
+// DefaultNegotiatorCapacity is used.
+func NewNegotiator(availableMediaTypes []MediaType, capacity int) *Negotiator {
+	if capacity <= 0 {
+		capacity = DefaultNegotiatorCapacity
+	}
+	return &Negotiator{
+		availableMediaTypes: append([]MediaType(nil), availableMediaTypes...),
+		capacity:            capacity,
+		entries:             make(map[string]*list.Element),
+		order:               list.New(),
+	}
+}
+
+// Chooses a media type according to the Accept header of the request. A request
+// without an Accept header is given the first available media type, exactly as
+// MatchAcceptableMediaType does.
+func (n *Negotiator) Match(request *http.Request) (MediaType, Parameters, error) {
+	if len(n.availableMediaTypes) == 0 {
+		return MediaType{}, Parameters{}, ErrNoAvailableTypeGiven
+	}
+
+	acceptHeaders := request.Header.Values("Accept")
+	if len(acceptHeaders) == 0 {
+		return n.availableMediaTypes[0], Parameters{}, nil
+	}
+
+	return n.Negotiate(acceptHeaders[0])
+}
+
+// Chooses a media type according to the value of an Accept header.
+func (n *Negotiator) Negotiate(header string) (MediaType, Parameters, error) {
+	if len(n.availableMediaTypes) == 0 {
+		return MediaType{}, Parameters{}, ErrNoAvailableTypeGiven
+	}
+
+	if result, ok := n.lookup(header); ok {
+		n.hits.Add(1)
+		return result.mediaType, copyParameters(result.extensionParameters), result.err
+	}
+	n.misses.Add(1)
+
+	result := &negotiation{header: header}
+	ranges, err := newParser("Accept", header, false).parseAccept()
+	if err != nil {
+		result.mediaType, result.extensionParameters, result.err = MediaType{}, Parameters{}, err
+	} else {
+		result.mediaType, result.extensionParameters, result.err = chooseMediaType(ranges, n.availableMediaTypes)
+	}
+
+	n.store(result)
+	return result.mediaType, copyParameters(result.extensionParameters), result.err
+}
+
+// Returns the number of negotiations answered from the cache and the number
+// which had to be computed.
+func (n *Negotiator) Stats() (hits, misses uint64) {
+	return n.hits.Load(), n.misses.Load()
+}
+
+// Returns the number of Accept headers currently remembered.
+func (n *Negotiator) Len() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.order.Len()
+}
+
+func (n *Negotiator) lookup(header string) (*negotiation, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	e, ok := n.entries[header]
+	if !ok {
+		return nil, false
+	}
+	n.order.MoveToFront(e)
+	return e.Value.(*negotiation), true
+}
+
+func (n *Negotiator) store(result *negotiation) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if e, ok := n.entries[result.header]; ok { // raced with another caller
+		n.order.MoveToFront(e)
+		return
+	}
+	n.entries[result.header] = n.order.PushFront(result)
+	for n.order.Len() > n.capacity {
+		e := n.order.Back()
+		n.order.Remove(e)
+		delete(n.entries, e.Value.(*negotiation).header)
+	}
+}
+
+// Extension parameters are copied out of the cache so callers cannot modify
+// the remembered result.
+func copyParameters(p Parameters) Parameters {
+	c := make(Parameters, len(p))
+	for k, v := range p {
+		c[k] = v
+	}
+	return c
+}
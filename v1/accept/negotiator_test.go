@@ -0,0 +1,107 @@
+package accept
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestNegotiator(t *testing.T) {
+	availableMediaTypes := []MediaType{
+		{"application", "json", Parameters{}},
+		{"text", "plain", Parameters{}},
+	}
+
+	testCases := []struct {
+		name                string
+		header              string
+		result              MediaType
+		extensionParameters Parameters
+		err                 error
+	}{
+		{"Type and subtype", "text/plain", MediaType{"text", "plain", Parameters{}}, Parameters{}, nil},
+		{"Weights", "application/json;q=0.5,text/plain", MediaType{"text", "plain", Parameters{}}, Parameters{}, nil},
+		{"Extension parameters", "application/*;q=1;e=f", MediaType{"application", "json", Parameters{}}, Parameters{"e": "f"}, nil},
+		{"No acceptable type", "image/png", MediaType{}, Parameters{}, ErrNoAcceptableTypeFound},
+		{"Invalid header", "a/b;c", MediaType{}, Parameters{}, ErrInvalidParameter},
+	}
+
+	n := NewNegotiator(availableMediaTypes, 0)
+	for round := 0; round < 2; round++ {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				result, extensionParameters, err := n.Negotiate(testCase.header)
+				if !errors.Is(err, testCase.err) {
+					t.Errorf("Unexpected error \"%v\", expected \"%v\" for %s", err, testCase.err, testCase.header)
+				} else if result.Type != testCase.result.Type || result.Subtype != testCase.result.Subtype {
+					t.Errorf("Invalid content type, got %s/%s, exptected %s/%s for %s", result.Type, result.Subtype, testCase.result.Type, testCase.result.Subtype, testCase.header)
+				} else if !reflect.DeepEqual(extensionParameters, testCase.extensionParameters) {
+					t.Errorf("Wrong extension parameters, got %v, expected %v for %s", extensionParameters, testCase.extensionParameters, testCase.header)
+				}
+			})
+		}
+	}
+
+	if hits, misses := n.Stats(); hits != uint64(len(testCases)) || misses != uint64(len(testCases)) {
+		t.Errorf("Unexpected stats, got %d hits and %d misses, expected %d of each", hits, misses, len(testCases))
+	}
+}
+
+func TestNegotiatorEviction(t *testing.T) {
+	n := NewNegotiator([]MediaType{{"a", "b", Parameters{}}}, 2)
+
+	for _, header := range []string{"a/b", "a/*", "a/b", "*/*", "a/b", "a/*"} {
+		if _, _, err := n.Negotiate(header); err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %s", err, header)
+		}
+	}
+
+	if l := n.Len(); l != 2 {
+		t.Errorf("Unexpected number of entries, got %d, expected 2", l)
+	}
+	// "a/b" is always recently used and stays; "a/*" is evicted by "*/*" and
+	// has to be negotiated again at the end
+	if hits, misses := n.Stats(); hits != 2 || misses != 4 {
+		t.Errorf("Unexpected stats, got %d hits and %d misses, expected 2 and 4", hits, misses)
+	}
+}
+
+func TestNegotiatorRequest(t *testing.T) {
+	n := NewNegotiator([]MediaType{{"a", "b", Parameters{}}, {"c", "d", Parameters{}}}, 0)
+
+	request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, _, err := n.Match(request)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	} else if result.Base() != "a/b" {
+		t.Errorf("Invalid content type, got %s, expected a/b", result.Base())
+	}
+	if _, misses := n.Stats(); misses != 0 {
+		t.Errorf("Requests without an Accept header should not be cached")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request, _ := http.NewRequest(http.MethodGet, "http://test.test", nil)
+			request.Header.Set("Accept", "c/d")
+			if result, _, err := n.Match(request); err != nil || result.Base() != "c/d" {
+				t.Errorf("Unexpected result %v, %v", result, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if hits, misses := n.Stats(); hits+misses != 16 {
+		t.Errorf("Unexpected stats, got %d hits and %d misses, expected 16 in total", hits, misses)
+	}
+}
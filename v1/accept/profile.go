@@ -0,0 +1,188 @@
+package accept
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// Profile in the Accept-Profile or Content-Profile header is syntactically invalid.
+	ErrInvalidProfile = errors.New("invalid profile")
+	// Accept-Profile header contains only profiles that are not in the acceptable profile list.
+	ErrNoAcceptableProfileFound = errors.New("no acceptable profile found")
+	// Acceptable profile list is empty.
+	ErrNoAvailableProfileGiven = errors.New("no available profile given")
+)
+
+// Reports whether a media type's profile parameter satisfies the profile
+// parameter of a media range. Both are whitespace-separated lists of URIs;
+// they are compatible when the media type conforms to any of the profiles
+// the range asks for. URIs are compared exactly, since they are case
+// sensitive.
+func compareProfiles(checkProfiles, profiles string) bool {
+	// RFC 6906, 3.1. Profile Media Type Parameter
+	for _, checkProfile := range strings.Fields(checkProfiles) {
+		for _, profile := range strings.Fields(profiles) {
+			if checkProfile == profile {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Returns the profiles listed by the media type's profile parameter.
+func (mediaType MediaType) Profiles() []string {
+	return strings.Fields(mediaType.Parameters["profile"])
+}
+
+// A profile URI listed in an Accept-Profile header and its weight.
+type WeightedProfile struct {
+	URI    string
+	Weight int // 0 to 1000
+}
+
+func consumeURIReference(s string) (string, string, bool) {
+	// RFC 8288, 3. Link Serialisation in HTTP Headers
+	s = skipWhiteSpaces(s)
+	if len(s) == 0 || s[0] != '<' {
+		return "", s, false
+	}
+
+	end := strings.IndexByte(s, '>')
+	if end < 0 {
+		return "", s, false
+	}
+
+	uri := s[1:end]
+	for i := 0; i < len(uri); i++ {
+		if uri[i] <= 0x20 || uri[i] == '<' || uri[i] >= 0x7F {
+			return "", s[1+i:], false
+		}
+	}
+
+	return uri, skipWhiteSpaces(s[end+1:]), true
+}
+
+// Parses the value of an Accept-Profile header into the profiles it lists, in
+// the order they appear.
+func ParseAcceptProfile(s string) ([]WeightedProfile, error) {
+	// W3C Content Negotiation by Profile, 7.1. Accept-Profile
+	p := newParser("Accept-Profile", s, false)
+	var profiles []WeightedProfile
+
+	for count := 0; len(s) > 0; count++ {
+		if count > 0 {
+			if s[0] != ',' {
+				return nil, p.fail(count, s, expected("a comma", s), ErrInvalidProfile)
+			}
+			s = s[1:] // skip the comma
+		}
+
+		uri, remaining, consumed := consumeURIReference(s)
+		if !consumed {
+			return nil, p.fail(count, remaining, expected("a URI in angle brackets", remaining), ErrInvalidProfile)
+		}
+		s = remaining
+
+		weight := 1000 // 1.000
+		for len(s) > 0 && s[0] == ';' {
+			s = s[1:] // skip the semicolon
+
+			key, value, remaining, consumed := consumeParameter(s)
+			if !consumed {
+				return nil, p.fail(count, remaining, expected("a parameter", remaining), ErrInvalidParameter)
+			}
+
+			if key == "q" {
+				if weight, consumed = getWeight(value); !consumed {
+					return nil, p.fail(count, s, fmt.Sprintf("weight %q is not a number between 0 and 1 with at most three decimals", value), ErrInvalidWeight)
+				}
+			}
+
+			s = remaining
+		}
+
+		profiles = append(profiles, WeightedProfile{URI: uri, Weight: weight})
+		s = skipWhiteSpaces(s)
+	}
+
+	return profiles, nil
+}
+
+// Chooses a profile from the available profiles according to the Accept-Profile
+// header. If the request does not contain the header, the first available
+// profile is returned. Profile URIs are compared exactly.
+func MatchAcceptableProfile(request *http.Request, availableProfiles []string) (string, error) {
+	if len(availableProfiles) == 0 {
+		return "", ErrNoAvailableProfileGiven
+	}
+
+	acceptHeaders := request.Header.Values("Accept-Profile")
+	if len(acceptHeaders) == 0 {
+		return availableProfiles[0], nil
+	}
+
+	profiles, err := ParseAcceptProfile(strings.Join(acceptHeaders, ","))
+	if err != nil {
+		return "", err
+	}
+
+	result, resultWeight := "", 0
+	for _, profile := range profiles {
+		if profile.Weight <= resultWeight {
+			continue
+		}
+		for _, available := range availableProfiles {
+			if available == profile.URI {
+				result, resultWeight = available, profile.Weight
+				break
+			}
+		}
+	}
+
+	if result == "" {
+		return "", ErrNoAcceptableProfileFound
+	}
+
+	return result, nil
+}
+
+// Gets the profiles a request's content conforms to from its Content-Profile
+// header.
+func ParseContentProfile(request *http.Request) ([]string, error) {
+	// W3C Content Negotiation by Profile, 7.2. Content-Profile
+	var result []string
+	for _, header := range request.Header.Values("Content-Profile") {
+		profiles, err := ParseAcceptProfile(header)
+		if err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				parseErr.Header = "Content-Profile"
+			}
+			return nil, err
+		}
+		for _, profile := range profiles {
+			result = append(result, profile.URI)
+		}
+	}
+
+	return result, nil
+}
+
+// Sets the Content-Profile header to the profiles the content conforms to.
+func SetContentProfile(header http.Header, profiles ...string) {
+	var stringBuilder strings.Builder
+	for i, profile := range profiles {
+		if i > 0 {
+			stringBuilder.WriteString(", ")
+		}
+		stringBuilder.WriteByte('<')
+		stringBuilder.WriteString(profile)
+		stringBuilder.WriteByte('>')
+	}
+	header.Set("Content-Profile", stringBuilder.String())
+}
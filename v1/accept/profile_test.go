@@ -0,0 +1,114 @@
+package accept
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMatchAcceptableMediaTypeProfile(t *testing.T) {
+	availableMediaTypes := []MediaType{
+		JSON.With("profile", "https://example.com/v1"),
+		JSON.With("profile", "https://example.com/v2"),
+		JSON.With("profile", "https://example.com/Schema"),
+	}
+
+	testCases := []struct {
+		name   string
+		header string
+		result MediaType
+		err    error
+	}{
+		{"No profile", "application/json", availableMediaTypes[0], nil},
+		{"Single profile", `application/json;profile="https://example.com/v2"`, availableMediaTypes[1], nil},
+		{"Profile list", `application/json;profile="https://example.com/v3 https://example.com/v2"`, availableMediaTypes[1], nil},
+		{"Weighted profiles", `application/json;profile="https://example.com/v1";q=0.5, application/json;profile="https://example.com/v2"`, availableMediaTypes[1], nil},
+		{"Unknown profile", `application/json;profile="https://example.com/v3"`, MediaType{}, ErrNoAcceptableTypeFound},
+		{"Profile case preserved", `application/json;profile="https://example.com/Schema"`, availableMediaTypes[2], nil},
+		{"Profile case sensitive", `application/json;profile="https://example.com/schema"`, MediaType{}, ErrNoAcceptableTypeFound},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			request.Header.Set("Accept", testCase.header)
+
+			result, _, err := MatchAcceptableMediaType(request, availableMediaTypes)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\" for %s", err, testCase.err, testCase.header)
+			} else if err == nil && !reflect.DeepEqual(result, testCase.result) {
+				t.Errorf("Invalid media type, got %v, expected %v for %s", result, testCase.result, testCase.header)
+			}
+		})
+	}
+}
+
+func TestMatchAcceptableProfile(t *testing.T) {
+	availableProfiles := []string{"urn:example:a", "http://example.com/B"}
+
+	testCases := []struct {
+		name   string
+		header string
+		result string
+		err    error
+	}{
+		{"Empty header", "", "urn:example:a", nil},
+		{"Single profile", "<http://example.com/B>", "http://example.com/B", nil},
+		{"Weighted profiles", "<urn:example:a>;q=0.5, <http://example.com/B>;q=0.8", "http://example.com/B", nil},
+		{"Unknown profiles ignored", "<urn:example:c>, <urn:example:a>;q=0.1", "urn:example:a", nil},
+		{"Case sensitive", "<http://example.com/b>", "", ErrNoAcceptableProfileFound},
+		{"Zero weight", "<urn:example:a>;q=0", "", ErrNoAcceptableProfileFound},
+		{"Missing brackets", "urn:example:a", "", ErrInvalidProfile},
+		{"Invalid weight", "<urn:example:a>;q=2", "", ErrInvalidWeight},
+		{"Missing comma", "<urn:example:a> <urn:example:b>", "", ErrInvalidProfile},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if len(testCase.header) > 0 {
+				request.Header.Set("Accept-Profile", testCase.header)
+			}
+
+			result, err := MatchAcceptableProfile(request, availableProfiles)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\" for %s", err, testCase.err, testCase.header)
+			} else if result != testCase.result {
+				t.Errorf("Invalid profile, got %s, expected %s for %s", result, testCase.result, testCase.header)
+			}
+		})
+	}
+}
+
+func TestContentProfile(t *testing.T) {
+	request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	SetContentProfile(request.Header, "urn:example:a", "http://example.com/B")
+	if result := request.Header.Get("Content-Profile"); result != "<urn:example:a>, <http://example.com/B>" {
+		t.Errorf("Invalid header, got %s", result)
+	}
+
+	profiles, err := ParseContentProfile(request)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	} else if !reflect.DeepEqual(profiles, []string{"urn:example:a", "http://example.com/B"}) {
+		t.Errorf("Invalid profiles, got %v", profiles)
+	}
+
+	request.Header.Set("Content-Profile", "urn:example:a")
+	var parseErr *ParseError
+	if _, err := ParseContentProfile(request); !errors.As(err, &parseErr) || parseErr.Header != "Content-Profile" {
+		t.Errorf("Expected a Content-Profile parse error, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package accept
+
+import (
+	"sort"
+	"strings"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+// Media types mirroring the types defined by the mime package. Use With to
+// derive a variant with parameters rather than modifying these directly.
+var (
+	Text     = MustMediaType(mime.Text.String())
+	Markdown = MustMediaType(mime.Markdown.String())
+	HTML     = MustMediaType(mime.HTML.String())
+	JSON     = MustMediaType(mime.JSON.String())
+	CSV      = MustMediaType(mime.CSV.String())
+	XML      = MustMediaType(mime.XML.String())
+	GZIP     = MustMediaType(mime.GZIP.String())
+	Any      = Wildcard("*")
+)
+
+// Creates a media type from a type and subtype, without any parameters.
+func Of(t, subt string) MediaType {
+	return MediaType{
+		Type:       strings.ToLower(t),
+		Subtype:    strings.ToLower(subt),
+		Parameters: Parameters{},
+	}
+}
+
+// Creates a media range which matches any subtype of the type, such as
+// "text/*". If the type is empty or "*" the range matches any media type.
+func Wildcard(t string) MediaType {
+	if t == "" {
+		t = "*"
+	}
+	return Of(t, "*")
+}
+
+// Returns a copy of the media type with the parameter set. The receiver is
+// not modified, so calls can be chained starting from a shared value:
+//
+//	accept.JSON.With("charset", "utf-8")
+func (mediaType MediaType) With(key, value string) MediaType {
+	parameters := make(Parameters, len(mediaType.Parameters)+1)
+	for k, v := range mediaType.Parameters {
+		parameters[k] = v
+	}
+	parameters[strings.ToLower(key)] = value
+	mediaType.Parameters = parameters
+	return mediaType
+}
+
+// Converts a mime.Type into a MediaType.
+func FromType(t mime.Type) (MediaType, error) {
+	return ParseMediaTypeString(t.String())
+}
+
+// Converts the media type into a mime.Type, with parameters sorted and
+// quoted in the same canonical form produced by mime.Parse.
+func (mediaType MediaType) Mime() mime.Type {
+	if len(mediaType.Type) == 0 && len(mediaType.Subtype) == 0 {
+		return mime.Invalid
+	}
+
+	var stringBuilder strings.Builder
+	stringBuilder.WriteString(mediaType.Type)
+	stringBuilder.WriteByte('/')
+	stringBuilder.WriteString(mediaType.Subtype)
+
+	keys := make([]string, 0, len(mediaType.Parameters))
+	for k := range mediaType.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		stringBuilder.WriteByte(';')
+		stringBuilder.WriteString(key)
+		stringBuilder.WriteByte('=')
+		stringBuilder.WriteString(lex.Quote(mediaType.Parameters[key]))
+	}
+
+	return mime.Type(stringBuilder.String())
+}
@@ -0,0 +1,97 @@
+package accept
+
+import (
+	"reflect"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestPredefinedMediaTypes(t *testing.T) {
+	testCases := []struct {
+		value  MediaType
+		result mime.Type
+	}{
+		{Text, mime.Text},
+		{Markdown, mime.Markdown},
+		{HTML, mime.HTML},
+		{JSON, mime.JSON},
+		{CSV, mime.CSV},
+		{XML, mime.XML},
+		{GZIP, mime.GZIP},
+		{Any, mime.Type("*/*")},
+	}
+
+	for _, testCase := range testCases {
+		if result := testCase.value.Mime(); result != testCase.result {
+			t.Errorf("Invalid media type, got %s, expected %s", result, testCase.result)
+		}
+	}
+}
+
+func TestBuilders(t *testing.T) {
+	testCases := []struct {
+		name   string
+		value  MediaType
+		result MediaType
+	}{
+		{"Type and subtype", Of("Application", "JSON"), MediaType{"application", "json", Parameters{}}},
+		{"Parameters", Of("text", "plain").With("Charset", "utf-8").With("a", "b"), MediaType{"text", "plain", Parameters{"charset": "utf-8", "a": "b"}}},
+		{"Predefined with parameter", JSON.With("charset", "utf-8"), MediaType{"application", "json", Parameters{"charset": "utf-8"}}},
+		{"Wildcard subtype", Wildcard("text"), MediaType{"text", "*", Parameters{}}},
+		{"Wildcard", Wildcard(""), MediaType{"*", "*", Parameters{}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if !reflect.DeepEqual(testCase.value, testCase.result) {
+				t.Errorf("Invalid media type, got %v, expected %v", testCase.value, testCase.result)
+			}
+		})
+	}
+
+	if len(JSON.Parameters) != 0 {
+		t.Errorf("With must not modify the predefined media type, got %v", JSON.Parameters)
+	}
+}
+
+func TestMimeConversion(t *testing.T) {
+	mediaType, err := FromType(mime.Type("text/plain;charset=utf-8;a=b"))
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	}
+	if !reflect.DeepEqual(mediaType, Text.With("charset", "utf-8").With("a", "b")) {
+		t.Errorf("Invalid media type, got %v", mediaType)
+	}
+	if result := mediaType.Mime(); result != mime.Type("text/plain;a=b;charset=utf-8") {
+		t.Errorf("Invalid mime type, got %s", result)
+	}
+	if result := (MediaType{}).Mime(); result != mime.Invalid {
+		t.Errorf("Expected an invalid mime type, got %s", result)
+	}
+}
+
+func TestMimeQuoting(t *testing.T) {
+	testCases := []struct {
+		value  MediaType
+		result mime.Type
+	}{
+		{MustMediaType(`application/json; profile="https://example.com/v2"`), `application/json;profile="https://example.com/v2"`},
+		{CSV.With("delimiter", ";").With("header", "present"), `text/csv;delimiter=";";header=present`},
+		{Text.With("note", `say "hi" \o/`), `text/plain;note="say \"hi\" \\o/"`},
+		{Text.With("empty", ""), `text/plain;empty=""`},
+	}
+
+	for _, testCase := range testCases {
+		result := testCase.value.Mime()
+		if result != testCase.result {
+			t.Errorf("Unexpected type %s, expected %s", result, testCase.result)
+		}
+		parsed, params, err := mime.Parse(result.String())
+		if err != nil {
+			t.Errorf("Unexpected error \"%v\" for %s", err, result)
+		} else if parsed != result || !reflect.DeepEqual(params, map[string]string(testCase.value.Parameters)) {
+			t.Errorf("Unexpected round trip %s %v, expected %s %v", parsed, params, result, testCase.value.Parameters)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package accept
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// A VersionRouter dispatches requests to handlers registered for different
+// versions of vendor media types, like application/vnd.acme.v3+json, according
+// to the Accept header of the request.
+//
+// A media range naming a version only matches that version. Ranges which
+// leave the version open, such as application/vnd.acme+json, the bare suffix
+// type application/json, or wildcards, match every registered version, and
+// the highest acceptable version is chosen. Between equally weighted ranges,
+// an explicitly requested version is preferred.
+type VersionRouter struct {
+	routes []versionRoute
+	// Handles requests for which no registered version is acceptable. If it
+	// is nil a 406 Not Acceptable response is written.
+	NotAcceptable http.Handler
+}
+
+type versionRoute struct {
+	mediaType MediaType
+	vendor    mime.Vendor
+	handler   http.Handler
+}
+
+// How closely a media range matches a route, from least to most specific.
+const (
+	versionMatchAny = iota
+	versionMatchSubtypeWildcard
+	versionMatchSuffix
+	versionMatchProduct
+	versionMatchVersion
+)
+
+// Creates an empty VersionRouter.
+func NewVersionRouter() *VersionRouter {
+	return &VersionRouter{}
+}
+
+// Registers the handler for a vendor media type. It panics if the type is not
+// a vendor type or has already been registered, like http.ServeMux does for
+// conflicting patterns. Versions are compared numerically, so v3 and v3.0
+// are the same version.
+func (router *VersionRouter) Handle(t mime.Type, handler http.Handler) {
+	vendor, ok := t.Vendor()
+	if !ok {
+		panic(fmt.Sprintf("accept: %q is not a vendor media type", t))
+	}
+	mediaType, err := FromType(t)
+	if err != nil {
+		panic(fmt.Sprintf("accept: %q is not a valid media type: %v", t, err))
+	}
+	for _, route := range router.routes {
+		if route.mediaType.Type == mediaType.Type && route.vendor.SameProduct(vendor) && route.vendor.CompareVersion(vendor) == 0 {
+			panic(fmt.Sprintf("accept: multiple registrations for %q", t))
+		}
+	}
+	router.routes = append(router.routes, versionRoute{mediaType: mediaType, vendor: vendor, handler: handler})
+}
+
+// Registers the handler function for a vendor media type.
+func (router *VersionRouter) HandleFunc(t mime.Type, handler func(http.ResponseWriter, *http.Request)) {
+	router.Handle(t, http.HandlerFunc(handler))
+}
+
+// Chooses the registered media type and handler for the request. A request
+// without an Accept header is given the highest registered version.
+func (router *VersionRouter) Match(request *http.Request) (MediaType, http.Handler, error) {
+	if len(router.routes) == 0 {
+		return MediaType{}, nil, ErrNoAvailableTypeGiven
+	}
+
+	ranges := []mediaRange{{mediaType: Any, weight: 1000}}
+	if acceptHeaders := request.Header.Values("Accept"); len(acceptHeaders) > 0 {
+		var err error
+		ranges, err = newParser("Accept", acceptHeaders[0], false).parseAccept()
+		if err != nil {
+			return MediaType{}, nil, err
+		}
+	}
+
+	resultIndex, resultWeight, resultMatch := -1, 0, 0
+	for i, route := range router.routes {
+		weight, match := 0, -1
+		for _, acceptable := range ranges {
+			if m, ok := matchVersion(acceptable.mediaType, route); ok && m > match {
+				weight, match = acceptable.weight, m
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		explicit, resultExplicit := match == versionMatchVersion, resultMatch == versionMatchVersion
+		if resultIndex == -1 ||
+			weight > resultWeight ||
+			(weight == resultWeight && explicit && !resultExplicit) ||
+			(weight == resultWeight && explicit == resultExplicit && route.vendor.CompareVersion(router.routes[resultIndex].vendor) > 0) {
+			resultIndex, resultWeight, resultMatch = i, weight, match
+		}
+	}
+
+	if resultIndex == -1 {
+		return MediaType{}, nil, ErrNoAcceptableTypeFound
+	}
+
+	return router.routes[resultIndex].mediaType, router.routes[resultIndex].handler, nil
+}
+
+// Reports whether the media range matches the route and how specifically.
+func matchVersion(checkMediaType MediaType, route versionRoute) (int, bool) {
+	if checkMediaType.Type == route.mediaType.Type {
+		if vendor, ok := checkMediaType.Mime().Vendor(); ok {
+			switch {
+			case !vendor.SameProduct(route.vendor):
+				return 0, false
+			case vendor.Version == "":
+				return versionMatchProduct, true
+			case vendor.CompareVersion(route.vendor) == 0:
+				return versionMatchVersion, true
+			default:
+				return 0, false
+			}
+		}
+		if route.vendor.Suffix != "" && checkMediaType.Subtype == route.vendor.Suffix {
+			return versionMatchSuffix, true
+		}
+	}
+
+	switch {
+	case checkMediaType.Type == "*" && checkMediaType.Subtype == "*":
+		return versionMatchAny, true
+	case checkMediaType.Type == route.mediaType.Type && checkMediaType.Subtype == "*":
+		return versionMatchSubtypeWildcard, true
+	default:
+		return 0, false
+	}
+}
+
+// Dispatches the request to the handler for the chosen version, which can
+// retrieve the chosen media type with MediaTypeFromContext. Invalid Accept
+// headers produce a 400 Bad Request response.
+func (router *VersionRouter) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	w.Header().Add("Vary", "Accept")
+
+	mediaType, handler, err := router.Match(request)
+	var parseErr *ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		http.Error(w, parseErr.Error(), http.StatusBadRequest)
+	case err != nil && router.NotAcceptable != nil:
+		router.NotAcceptable.ServeHTTP(w, request)
+	case err != nil:
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+	default:
+		handler.ServeHTTP(w, request.WithContext(WithMediaType(request.Context(), mediaType)))
+	}
+}
@@ -0,0 +1,98 @@
+package accept
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func newVersionRouter() *VersionRouter {
+	router := NewVersionRouter()
+	for _, t := range []mime.Type{
+		"application/vnd.acme.v1+json",
+		"application/vnd.acme.v3+json",
+		"application/vnd.acme.v2+json",
+		"application/vnd.acme.v2+xml",
+	} {
+		router.HandleFunc(t, func(w http.ResponseWriter, request *http.Request) {
+			mediaType, _ := MediaTypeFromContext(request.Context())
+			io.WriteString(w, mediaType.String())
+		})
+	}
+	return router
+}
+
+func TestVersionRouter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		status int
+		result string
+	}{
+		{"No header", "", http.StatusOK, "application/vnd.acme.v3+json"},
+		{"Wildcard", "*/*", http.StatusOK, "application/vnd.acme.v3+json"},
+		{"Explicit version", "application/vnd.acme.v2+json", http.StatusOK, "application/vnd.acme.v2+json"},
+		{"Explicit version with suffix", "application/vnd.acme.v2+xml", http.StatusOK, "application/vnd.acme.v2+xml"},
+		{"Version parameter", "application/vnd.acme+json;version=1", http.StatusOK, "application/vnd.acme.v1+json"},
+		{"Unversioned vendor type", "application/vnd.acme+json", http.StatusOK, "application/vnd.acme.v3+json"},
+		{"Suffix type", "application/json", http.StatusOK, "application/vnd.acme.v3+json"},
+		{"Suffix type xml", "application/xml", http.StatusOK, "application/vnd.acme.v2+xml"},
+		{"Explicit version preferred to wildcard", "application/vnd.acme.v1+json, */*", http.StatusOK, "application/vnd.acme.v1+json"},
+		{"Weights", "application/vnd.acme.v1+json, application/vnd.acme.v3+json;q=0.5", http.StatusOK, "application/vnd.acme.v1+json"},
+		{"Excluded version", "application/vnd.acme.v3+json;q=0, application/json", http.StatusOK, "application/vnd.acme.v2+json"},
+		{"Unknown version", "application/vnd.acme.v4+json", http.StatusNotAcceptable, ""},
+		{"Other product", "application/vnd.other+json", http.StatusNotAcceptable, ""},
+		{"Other type", "text/html", http.StatusNotAcceptable, ""},
+		{"Invalid header", "application/json;q=2", http.StatusBadRequest, ""},
+	}
+
+	router := newVersionRouter()
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, "http://test.test", nil)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if len(testCase.header) > 0 {
+				request.Header.Set("Accept", testCase.header)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != testCase.status {
+				t.Errorf("Unexpected status %d, expected %d for %s", recorder.Code, testCase.status, testCase.header)
+			} else if testCase.status == http.StatusOK && recorder.Body.String() != testCase.result {
+				t.Errorf("Invalid media type, got %s, expected %s for %s", recorder.Body.String(), testCase.result, testCase.header)
+			}
+			if vary := recorder.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("Missing Vary header, got %q", vary)
+			}
+		})
+	}
+}
+
+func TestVersionRouterRegistration(t *testing.T) {
+	testCases := []struct {
+		name string
+		t    mime.Type
+	}{
+		{"Not a vendor type", mime.JSON},
+		{"Duplicate registration", "application/vnd.acme.v1+json"},
+		{"Equivalent version", "application/vnd.acme.v1.0+json"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for %s", testCase.t)
+				}
+			}()
+			newVersionRouter().Handle(testCase.t, http.NotFoundHandler())
+		})
+	}
+}
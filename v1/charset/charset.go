@@ -0,0 +1,311 @@
+// Package charset detects the character encoding of text, so that the
+// charset parameter of a type can be filled in when content does not
+// declare it, as is common for text/csv exported from spreadsheets.
+package charset
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// Encodings Detect recognizes, by their preferred IANA names.
+const (
+	UTF8        = mime.UTF_8
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Windows1252 = "windows-1252"
+	Windows1250 = "windows-1250"
+	Windows1251 = "windows-1251"
+	KOI8R       = "koi8-r"
+)
+
+// Result describes the encoding detected for content.
+type Result struct {
+	Charset    string  // the name of the encoding, or empty if the content is not text
+	Confidence float64 // between 0 and 1
+	BOM        bool    // the content starts with a byte order mark
+}
+
+// Byte order marks, per the WHATWG Encoding Standard, section 6, "decode".
+var boms = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0xef, 0xbb, 0xbf}, UTF8},
+	{[]byte{0xfe, 0xff}, UTF16BE},
+	{[]byte{0xff, 0xfe}, UTF16LE},
+}
+
+// Detects the encoding of text from its leading bytes. In order, it checks
+// for a byte order mark, the null bytes UTF-16 puts in every other position,
+// valid UTF-8 and finally scores the content as each of the common single
+// byte encodings. A UTF-8 sequence truncated at the end of the content is
+// ignored, so a prefix of a larger document can be examined.
+//
+// Content which contains only ASCII is reported as UTF-8 with a confidence
+// of one half, since it is valid in every encoding Detect recognizes. Binary
+// content has no charset.
+func Detect(b []byte) Result {
+	for _, e := range boms {
+		if bytes.HasPrefix(b, e.bom) {
+			return Result{Charset: e.charset, Confidence: 1, BOM: true}
+		}
+	}
+	if len(b) == 0 {
+		return Result{}
+	}
+	if r, ok := detectUTF16(b); ok {
+		return r
+	}
+	if hasBinaryData(b) {
+		return Result{}
+	}
+	if n, ok := validUTF8(b); ok {
+		if n == 0 {
+			return Result{Charset: UTF8, Confidence: 0.5}
+		}
+		// a sequence of bytes over 0x7F is rarely valid UTF-8 by chance, so
+		// each one found halves the chance that the content is not UTF-8
+		c := 1 - 1/float64(uint(2)<<min(n, 6))
+		return Result{Charset: UTF8, Confidence: c}
+	}
+	return detectSingleByte(b)
+}
+
+// Fills in the charset parameter of a text type which does not have one with
+// the encoding detected from its content. Types which are not text, already
+// have a charset or whose content is not text are returned unchanged.
+func Apply(t mime.Type, b []byte) mime.Type {
+	if !t.IsText() || t.Param("charset") != "" {
+		return t
+	}
+	r := Detect(b)
+	if r.Charset == "" {
+		return t
+	}
+	return t.WithParam("charset", r.Charset)
+}
+
+// Detects UTF-16 without a byte order mark from the null bytes text which is
+// mostly ASCII has in the high byte of each code unit.
+func detectUTF16(b []byte) (Result, bool) {
+	pairs := len(b) / 2
+	if pairs < 2 {
+		return Result{}, false
+	}
+	var even, odd int
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 {
+			even++
+		}
+		if b[i+1] == 0 {
+			odd++
+		}
+	}
+	var (
+		charset     string
+		zero, other int
+	)
+	switch {
+	case odd > even:
+		charset, zero, other = UTF16LE, odd, even
+	case even > odd:
+		charset, zero, other = UTF16BE, even, odd
+	default:
+		return Result{}, false
+	}
+	// more than a third of the high bytes must be null, and nulls must be
+	// rare in the low bytes
+	if zero*3 < pairs || other*10 > pairs {
+		return Result{}, false
+	}
+	if hasBinaryData(ascii16(b, charset == UTF16BE)) {
+		return Result{}, false
+	}
+	return Result{Charset: charset, Confidence: float64(zero-other) / float64(pairs)}, true
+}
+
+// Returns the code units of UTF-16 content which are in the ASCII range, as
+// bytes.
+func ascii16(b []byte, bigEndian bool) []byte {
+	var s []byte
+	for i := 0; i+1 < len(b); i += 2 {
+		hi, lo := b[i+1], b[i]
+		if bigEndian {
+			hi, lo = lo, hi
+		}
+		if hi == 0 && lo < 0x80 {
+			s = append(s, lo)
+		}
+	}
+	return s
+}
+
+func hasBinaryData(b []byte) bool {
+	for _, c := range b {
+		// WHATWG MIME Sniffing, 3. binary data byte
+		if c <= 0x08 || c == 0x0b || (c >= 0x0e && c <= 0x1a) || (c >= 0x1c && c <= 0x1f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether the content is valid UTF-8 and returns the number of
+// multibyte sequences it contains.
+func validUTF8(b []byte) (int, bool) {
+	var n int
+	for len(b) > 0 {
+		if b[0] < utf8.RuneSelf {
+			b = b[1:]
+			continue
+		}
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size <= 1 {
+			// a sequence cut off at the end of the content is not an error
+			return n, !utf8.FullRune(b) && len(b) < utf8.UTFMax
+		}
+		b = b[size:]
+		n++
+	}
+	return n, true
+}
+
+// A single byte encoding Detect scores content against.
+type encoding struct {
+	charset string
+	table   *[128]rune
+	latin   bool   // the encoding is for a Latin alphabet, not Cyrillic
+	common  string // letters which are frequent in the languages it is used for
+}
+
+// Candidate encodings, in the order ties are resolved.
+var encodings = []encoding{
+	{Windows1252, &windows1252Table, true, "àáâäçèéêëíîïñóôöùúûüßÀÁÂÄÇÈÉÊÍÑÓÖÚÜåæøÅÆØ"},
+	{Windows1250, &windows1250Table, true, "áäčďéěíĺľňóôöőřŕšťúůüűýžąćęłńśźżÁČĎÉĚÍŇÓŘŠŤÚŮÝŽĄĆĘŁŃŚŹŻ"},
+	{Windows1251, &windows1251Table, false, "абвгдежзийклмнопрстуфхцчшщыьэюяіїєґ"},
+	{KOI8R, &koi8rTable, false, "абвгдежзийклмнопрстуфхцчшщыьэюя"},
+}
+
+// Punctuation and symbols over 0x7F which are common in text, and so do not
+// count against an encoding.
+const commonPunctuation = " «»–—‘’‚“”„…•€£§°©®™¡¿·№"
+
+// Scores the content as each single byte encoding and returns the best. A
+// letter scores more if it is frequent in the languages the encoding is used
+// for, and if it sits in a word the way that alphabet does: accented Latin
+// letters mostly appear beside ASCII letters, while Cyrillic words are made
+// only of bytes over 0x7F. Unusual symbols and capitals inside words score
+// less, and an encoding which does not define a byte is never chosen.
+func detectSingleByte(b []byte) Result {
+	var (
+		best, second = -1, -1
+		scores       = make([]int, len(encodings))
+		high         int
+	)
+	for _, c := range b {
+		if c >= 0x80 {
+			high++
+		}
+	}
+	for i, e := range encodings {
+		score, ok := e.score(b)
+		if !ok {
+			continue
+		}
+		scores[i] = score
+		switch {
+		case best < 0 || score > scores[best]:
+			best, second = i, best
+		case second < 0 || score > scores[second]:
+			second = i
+		}
+	}
+	if best < 0 {
+		return Result{}
+	}
+
+	// confidence grows with the margin over the runner up, relative to the
+	// most a byte can score
+	var margin float64
+	if second < 0 {
+		margin = 1
+	} else {
+		margin = float64(scores[best]-scores[second]) / float64(3*high)
+	}
+	c := 0.4 + margin
+	if scores[best] <= 0 {
+		c = 0.1
+	}
+	return Result{Charset: encodings[best].charset, Confidence: min(max(c, 0.1), 0.9)}
+}
+
+// Scores the content decoded with the encoding, or reports false if it has a
+// byte which the encoding does not define.
+func (e encoding) score(b []byte) (int, bool) {
+	var (
+		score int
+		prev  rune
+	)
+	for i, c := range b {
+		if c < 0x80 {
+			prev = rune(c)
+			continue
+		}
+		r := e.table[c-0x80]
+		if r == utf8.RuneError {
+			return 0, false
+		}
+		if !unicode.IsLetter(r) {
+			if !containsRune(commonPunctuation, r) {
+				score--
+			}
+			prev = r
+			continue
+		}
+
+		score++
+		if containsRune(e.common, r) {
+			score++
+		}
+		var next byte
+		if i+1 < len(b) {
+			next = b[i+1]
+		}
+		if isASCIILetter(prev) || isASCIILetter(rune(next)) {
+			if e.latin {
+				score++
+			} else {
+				score--
+			}
+		}
+		if prev >= 0x80 && unicode.IsLetter(prev) {
+			if e.latin {
+				score--
+			} else {
+				score++
+			}
+		}
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			score -= 2
+		}
+		prev = r
+	}
+	return score, true
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func containsRune(s string, r rune) bool {
+	for _, e := range s {
+		if e == r {
+			return true
+		}
+	}
+	return false
+}
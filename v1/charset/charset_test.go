@@ -0,0 +1,123 @@
+package charset
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// Encodes text with a single byte encoding's table.
+func encode(s string, table *[128]rune) []byte {
+	var b []byte
+	for _, r := range s {
+		if r < 0x80 {
+			b = append(b, byte(r))
+			continue
+		}
+		for i, e := range table {
+			if e == r {
+				b = append(b, byte(0x80+i))
+				break
+			}
+		}
+	}
+	return b
+}
+
+func encodeUTF16(s string, bigEndian bool) []byte {
+	var b []byte
+	for _, e := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b = append(b, byte(e>>8), byte(e))
+		} else {
+			b = append(b, byte(e), byte(e>>8))
+		}
+	}
+	return b
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   []byte
+		charset string
+		bom     bool
+	}{
+		{"UTF-8 BOM", []byte("\xef\xbb\xbfname,city\n"), UTF8, true},
+		{"UTF-16LE BOM", append([]byte{0xff, 0xfe}, encodeUTF16("name,city\n", false)...), UTF16LE, true},
+		{"UTF-16BE BOM", append([]byte{0xfe, 0xff}, encodeUTF16("name,city\n", true)...), UTF16BE, true},
+		{"UTF-16LE", encodeUTF16("name,city\nJosé,Zürich\n", false), UTF16LE, false},
+		{"UTF-16BE", encodeUTF16("name,city\nJosé,Zürich\n", true), UTF16BE, false},
+		{"UTF-8", []byte("name,city\nJosé,Zürich\n"), UTF8, false},
+		{"Truncated UTF-8", []byte("name,city\nJosé,Z\xc3"), UTF8, false},
+		{"ASCII", []byte("name,city\nJoe,Boston\n"), UTF8, false},
+		{"French", encode("Prénom;Nom;Ville\nFrançois;Lefèvre;Besançon\nHélène;Dupré;Orléans\n", &windows1252Table), Windows1252, false},
+		{"German", encode("Name;Straße;Größe\nJürgen Müller;Hauptstraße 5;groß\n", &windows1252Table), Windows1252, false},
+		{"Spanish", encode("Nombre;Año;Ciudad\nJosé Muñoz;1998;Málaga\n", &windows1252Table), Windows1252, false},
+		{"Smart quotes", encode("“Quoted” — it’s done…\n", &windows1252Table), Windows1252, false},
+		{"Polish", encode("Imię;Miasto\nZażółć gęślą jaźń;Łódź\nŁukasz;Kraków\n", &windows1250Table), Windows1250, false},
+		{"Czech", encode("Příliš žluťoučký kůň úpěl ďábelské ódy\n", &windows1250Table), Windows1250, false},
+		{"Russian", encode("Имя;Город\nИван Петров;Москва\nОльга;Санкт-Петербург\n", &windows1251Table), Windows1251, false},
+		{"Russian KOI8-R", encode("Имя;Город\nИван Петров;Москва\nОльга;Санкт-Петербург\n", &koi8rTable), KOI8R, false},
+		{"Binary", []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d, 0x01, 0x02}, "", false},
+		{"Empty", nil, "", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := Detect(testCase.input)
+			if result.Charset != testCase.charset {
+				t.Errorf("Unexpected charset %q, expected %q", result.Charset, testCase.charset)
+			}
+			if result.BOM != testCase.bom {
+				t.Errorf("Unexpected BOM %v, expected %v", result.BOM, testCase.bom)
+			}
+			if result.Confidence < 0 || result.Confidence > 1 {
+				t.Errorf("Unexpected confidence %v", result.Confidence)
+			}
+			if testCase.charset != "" && result.Confidence == 0 {
+				t.Errorf("Expected a confidence")
+			}
+		})
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	if c := Detect([]byte("\xef\xbb\xbfname")).Confidence; c != 1 {
+		t.Errorf("Unexpected confidence %v for a BOM", c)
+	}
+	one, many := Detect([]byte("José")).Confidence, Detect([]byte("José, Zürich, Genève")).Confidence
+	if one >= many {
+		t.Errorf("Expected more UTF-8 sequences to increase confidence, got %v and %v", one, many)
+	}
+	if c := Detect([]byte("ascii")).Confidence; c >= one {
+		t.Errorf("Expected ASCII to be less certain than UTF-8, got %v", c)
+	}
+}
+
+func TestApply(t *testing.T) {
+	excel := encode("Prénom;Nom\nHélène;Dupré\n", &windows1252Table)
+	testCases := []struct {
+		name   string
+		input  mime.Type
+		data   []byte
+		result mime.Type
+	}{
+		{"Undeclared", mime.CSV, excel, "text/csv;charset=windows-1252"},
+		{"Parsed", "text/csv;header=present", excel, "text/csv;charset=windows-1252;header=present"},
+		{"Declared", "text/csv; charset=utf-8", excel, "text/csv; charset=utf-8"},
+		{"UTF-8", mime.Text, []byte("Hélène"), "text/plain;charset=utf-8"},
+		{"JSON", mime.JSON, []byte(`{"name":"Hélène"}`), "application/json;charset=utf-8"},
+		{"Not text", "image/png", excel, "image/png"},
+		{"Binary", mime.Text, []byte{0x00, 0x01, 0x02, 0x03, 0x04}, mime.Text},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if result := Apply(testCase.input, testCase.data); result != testCase.result {
+				t.Errorf("Unexpected type %q, expected %q", result, testCase.result)
+			}
+		})
+	}
+}
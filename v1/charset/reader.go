@@ -0,0 +1,163 @@
+package charset
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var ErrUnsupportedCharset = errors.New("unsupported charset")
+
+// Labels of the encodings NewReader supports, per the WHATWG Encoding
+// Standard, section 4.2. As in browsers, ASCII and ISO-8859-1 are decoded
+// as windows-1252, which is a superset of both.
+var labels = map[string]string{
+	"utf-8":             UTF8,
+	"utf8":              UTF8,
+	"unicode-1-1-utf-8": UTF8,
+	"utf-16le":          UTF16LE,
+	"utf-16":            UTF16LE,
+	"utf-16be":          UTF16BE,
+	"windows-1252":      Windows1252,
+	"cp1252":            Windows1252,
+	"x-cp1252":          Windows1252,
+	"iso-8859-1":        Windows1252,
+	"iso8859-1":         Windows1252,
+	"latin1":            Windows1252,
+	"l1":                Windows1252,
+	"us-ascii":          Windows1252,
+	"ascii":             Windows1252,
+	"windows-1250":      Windows1250,
+	"cp1250":            Windows1250,
+	"x-cp1250":          Windows1250,
+	"windows-1251":      Windows1251,
+	"cp1251":            Windows1251,
+	"x-cp1251":          Windows1251,
+	"koi8-r":            KOI8R,
+	"koi8r":             KOI8R,
+	"koi8":              KOI8R,
+	"cskoi8r":           KOI8R,
+}
+
+// Returns the name of the encoding a label identifies, or reports false if
+// the encoding is not supported.
+func Lookup(label string) (string, bool) {
+	name, ok := labels[strings.ToLower(strings.TrimSpace(label))]
+	return name, ok
+}
+
+// Wraps a reader of text in the named encoding with a reader which produces
+// UTF-8. A byte order mark at the start of the content is removed.
+func NewReader(r io.Reader, label string) (io.Reader, error) {
+	name, ok := Lookup(label)
+	if !ok {
+		return nil, ErrUnsupportedCharset
+	}
+	switch name {
+	case UTF8:
+		return skipBOM(r, boms[0].bom), nil
+	case UTF16LE:
+		return &reader{r: skipBOM(r, boms[2].bom), decode: decodeUTF16(false)}, nil
+	case UTF16BE:
+		return &reader{r: skipBOM(r, boms[1].bom), decode: decodeUTF16(true)}, nil
+	}
+	for _, e := range encodings {
+		if e.charset == name {
+			return &reader{r: r, decode: decodeSingleByte(e.table)}, nil
+		}
+	}
+	return nil, ErrUnsupportedCharset
+}
+
+func skipBOM(r io.Reader, bom []byte) io.Reader {
+	b := bufio.NewReader(r)
+	if p, _ := b.Peek(len(bom)); bytes.Equal(p, bom) {
+		b.Discard(len(bom))
+	}
+	return b
+}
+
+// A reader which decodes the content of another as it is read.
+type reader struct {
+	r io.Reader
+	// Appends the UTF-8 encoding of the content to dst and returns the
+	// number of bytes of content decoded; at the end of the content every
+	// byte must be decoded.
+	decode func(dst, src []byte, eof bool) ([]byte, int)
+	buf    [4096]byte
+	in     []byte // content which has been read but not decoded
+	out    []byte // decoded content which has not been returned
+	err    error
+}
+
+func (d *reader) Read(b []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		n, err := d.r.Read(d.buf[:])
+		d.in = append(d.in, d.buf[:n]...)
+		d.err = err
+		var m int
+		d.out, m = d.decode(d.out[:0], d.in, err != nil)
+		d.in = append(d.in[:0], d.in[m:]...)
+	}
+	n := copy(b, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func decodeSingleByte(table *[128]rune) func([]byte, []byte, bool) ([]byte, int) {
+	return func(dst, src []byte, eof bool) ([]byte, int) {
+		for _, c := range src {
+			if c < 0x80 {
+				dst = append(dst, c)
+			} else {
+				dst = utf8.AppendRune(dst, table[c-0x80])
+			}
+		}
+		return dst, len(src)
+	}
+}
+
+func decodeUTF16(bigEndian bool) func([]byte, []byte, bool) ([]byte, int) {
+	unit := func(b []byte) rune {
+		if bigEndian {
+			return rune(b[0])<<8 | rune(b[1])
+		}
+		return rune(b[1])<<8 | rune(b[0])
+	}
+	return func(dst, src []byte, eof bool) ([]byte, int) {
+		var n int
+		for len(src)-n >= 2 {
+			r := unit(src[n:])
+			if utf16.IsSurrogate(r) && r < 0xdc00 {
+				if len(src)-n < 4 {
+					if !eof {
+						break // wait for the rest of the pair
+					}
+					r = utf8.RuneError
+				} else if s := utf16.DecodeRune(r, unit(src[n+2:])); s != utf8.RuneError {
+					dst = utf8.AppendRune(dst, s)
+					n += 4
+					continue
+				} else {
+					r = utf8.RuneError
+				}
+			} else if utf16.IsSurrogate(r) {
+				r = utf8.RuneError
+			}
+			dst = utf8.AppendRune(dst, r)
+			n += 2
+		}
+		if eof && n < len(src) {
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			n = len(src)
+		}
+		return dst, n
+	}
+}
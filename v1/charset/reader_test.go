@@ -0,0 +1,52 @@
+package charset
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewReader(t *testing.T) {
+	const text = "Prénom;Ville\nHélène;Zürich 😀\n"
+	testCases := []struct {
+		name  string
+		label string
+		input []byte
+		text  string
+		err   error
+	}{
+		{"UTF-8", "utf-8", []byte(text), text, nil},
+		{"UTF-8 BOM", "UTF8", []byte("\xef\xbb\xbf" + text), text, nil},
+		{"UTF-16LE", "utf-16le", encodeUTF16(text, false), text, nil},
+		{"UTF-16LE BOM", "utf-16", append([]byte{0xff, 0xfe}, encodeUTF16(text, false)...), text, nil},
+		{"UTF-16BE", "utf-16be", encodeUTF16(text, true), text, nil},
+		{"Truncated UTF-16", "utf-16le", []byte{'a', 0, 0x3d, 0xd8, 'b'}, "a��", nil},
+		{"Unpaired surrogate", "utf-16be", []byte{0xd8, 0x3d, 0, 'a'}, "�a", nil},
+		{"Windows-1252", "windows-1252", encode("Hélène — “Zürich”", &windows1252Table), "Hélène — “Zürich”", nil},
+		{"Latin 1", " ISO-8859-1 ", encode("Hélène", &windows1252Table), "Hélène", nil},
+		{"Windows-1250", "windows-1250", encode("Łódź", &windows1250Table), "Łódź", nil},
+		{"Windows-1251", "cp1251", encode("Москва", &windows1251Table), "Москва", nil},
+		{"KOI8-R", "koi8-r", encode("Москва", &koi8rTable), "Москва", nil},
+		{"Unsupported", "shift_jis", nil, "", ErrUnsupportedCharset},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r, err := NewReader(iotest.OneByteReader(strings.NewReader(string(testCase.input))), testCase.label)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			if err != nil {
+				return
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\"", err)
+			} else if string(data) != testCase.text {
+				t.Errorf("Unexpected text %q, expected %q", data, testCase.text)
+			}
+		})
+	}
+}
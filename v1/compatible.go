@@ -0,0 +1,85 @@
+package mime
+
+import (
+	"strings"
+)
+
+// The types of structured syntax suffixes, per RFC 6839 and the IANA
+// Structured Syntax Suffix registry: content with the suffix is also valid
+// as the suffix's type.
+var suffixTypes = map[string]Type{
+	"json": JSON,
+	"xml":  Type("application/xml"),
+	"yaml": Type("application/yaml"),
+	"zip":  Type("application/zip"),
+	"gzip": GZIP,
+	"cbor": Type("application/cbor"),
+}
+
+// compatibleWith returns the types content of the type can be served as
+// directly: those its registration lists, the type of its structured syntax
+// suffix and, for any text other than text/plain, text/plain, which RFC 2046
+// says unrecognized text is to be treated as.
+func (r *registry) compatibleWith(t Type) Options {
+	var result Options
+	if n, ok := r.types[t.String()]; ok {
+		result = append(result, r.regs[n].Compatible...)
+	}
+	if s, ok := suffixTypes[t.Suffix()]; ok && !s.Matches(t) {
+		result = append(result, s)
+	}
+	if t.IsText() && !t.Matches(Text) {
+		result = append(result, Text)
+	}
+	return result
+}
+
+// Fallbacks returns the types content of the type can also be served as,
+// nearest first, ignoring any parameters. For example, the fallbacks of
+// application/vnd.acme+json are application/json and text/plain, and the
+// fallback of text/markdown is text/plain. It generalizes
+// MarkdownCompatible to every type.
+func Fallbacks(t Type) Options {
+	base := Type(strings.ToLower(t.Base().String()))
+	if _, _, ok := base.split(); !ok || base.IsWildcard() {
+		return nil
+	}
+	r := loadRegistry()
+	var (
+		result Options
+		seen   = map[Type]struct{}{base: {}}
+		queue  = Options{base}
+	)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, e := range r.compatibleWith(next) {
+			if _, ok := seen[e]; ok {
+				continue
+			}
+			seen[e] = struct{}{}
+			result = append(result, e)
+			queue = append(queue, e)
+		}
+	}
+	return result
+}
+
+// Compatible reports whether content of type from can be served as type to,
+// because they are the same type or to is one of the fallbacks of from. The
+// type to may be a wildcard, like text/*.
+func Compatible(from, to Type) bool {
+	if from.IsWildcard() {
+		return false
+	}
+	o := Options{to}
+	if o.Match(from) {
+		return true
+	}
+	for _, e := range Fallbacks(from) {
+		if o.Match(e) {
+			return true
+		}
+	}
+	return false
+}
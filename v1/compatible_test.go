@@ -0,0 +1,57 @@
+package mime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbacks(t *testing.T) {
+	tests := []struct {
+		In        Type
+		Fallbacks Options
+	}{
+		{Markdown, Options{Text}},
+		{Type("text/markdown; variant=GFM"), Options{Text}},
+		{JSON, Options{Text}},
+		{Type("application/vnd.acme.v2+json"), Options{JSON, Text}},
+		{Type("application/problem+json"), Options{JSON, Text}},
+		{Type("application/xhtml+xml"), Options{"application/xml", Text, "text/xml"}},
+		{Type("image/svg+xml"), Options{"application/xml", Text, "text/xml"}},
+		{Type("application/xml"), Options{"text/xml", Text}},
+		{Type("application/javascript"), Options{"text/javascript", Text}},
+		{Type("application/epub+zip"), Options{"application/zip"}},
+		{Text, nil},
+		{Type("image/png"), nil},
+		{Type("text/*"), nil},
+		{Invalid, nil},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Fallbacks, Fallbacks(e.In), "#%d", i)
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	tests := []struct {
+		From, To   Type
+		Compatible bool
+	}{
+		{Markdown, Text, true},
+		{Markdown, Markdown, true},
+		{Type("TEXT/Markdown; charset=utf-8"), Type("text/plain; charset=utf-8"), true},
+		{Text, Markdown, false},
+		{Type("application/vnd.acme+json"), JSON, true},
+		{Type("application/vnd.acme+json"), Text, true},
+		{JSON, Type("application/vnd.acme+json"), false},
+		{Type("application/xhtml+xml"), Type("application/xml"), true},
+		{Type("application/xhtml+xml"), HTML, false},
+		{Type("image/svg+xml"), Type("text/*"), true},
+		{Type("image/png"), Type("image/*"), true},
+		{Type("image/png"), Text, false},
+		{Type("image/*"), Type("image/png"), false},
+		{Invalid, Text, false},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Compatible, Compatible(e.From, e.To), "#%d", i)
+	}
+}
@@ -0,0 +1,298 @@
+// Package convert converts content between types, so that a handler which
+// produces one type can respond with any type the content can be converted
+// to, as chosen by negotiation.
+//
+// Converters are registered for pairs of types in a Registry, and a Plan is
+// a sequence of conversions which leads from one type to another, as found
+// by the registry's planner.
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/accept"
+	"github.com/bww/go-mime/v1/charset"
+)
+
+var (
+	ErrNoConversion   = errors.New("no conversion found")
+	ErrInvalidContent = errors.New("invalid content")
+)
+
+// A Converter converts content to the type it is registered for. The type
+// of the content is given with its parameters, like its charset.
+type Converter interface {
+	Convert(dst io.Writer, src io.Reader, from mime.Type) error
+}
+
+// An ordinary function which converts content.
+type ConverterFunc func(dst io.Writer, src io.Reader, from mime.Type) error
+
+func (f ConverterFunc) Convert(dst io.Writer, src io.Reader, from mime.Type) error {
+	return f(dst, src, from)
+}
+
+type conversion struct {
+	from, to  mime.Type
+	converter Converter
+}
+
+// A Registry holds converters between pairs of types.
+type Registry struct {
+	lock        sync.RWMutex
+	conversions []conversion
+}
+
+// Creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry is the registry used by the package-level functions. It
+// has the built-in converters registered: Markdown to HTML and text, HTML to
+// text, JSON to XML and back, and CSV to JSON.
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(mime.Markdown, mime.HTML, ConverterFunc(markdownToHTML))
+	r.Register(mime.Markdown, mime.Text, ConverterFunc(markdownToText))
+	r.Register(mime.HTML, mime.Text, ConverterFunc(htmlToText))
+	r.Register(mime.JSON, "application/xml", ConverterFunc(jsonToXML))
+	r.Register("application/xml", mime.JSON, ConverterFunc(xmlToJSON))
+	r.Register(mime.CSV, mime.JSON, ConverterFunc(csvToJSON))
+	return r
+}
+
+func normalize(t mime.Type) mime.Type {
+	return mime.Type(strings.ToLower(t.Base().String()))
+}
+
+// Registers a converter from one type to another, replacing any converter
+// already registered for the pair. Parameters are ignored. The type
+// converted from may be a wildcard, like text/*, but the type converted to
+// may not. It panics if either type is invalid.
+func (r *Registry) Register(from, to mime.Type, c Converter) {
+	from, to = normalize(from), normalize(to)
+	if from.TopLevel() == "" || to.TopLevel() == "" || to.IsWildcard() {
+		panic(fmt.Sprintf("convert: invalid conversion from %q to %q", from, to))
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, e := range r.conversions {
+		if e.from == from && e.to == to {
+			r.conversions[i].converter = c
+			return
+		}
+	}
+	r.conversions = append(r.conversions, conversion{from: from, to: to, converter: c})
+}
+
+// Returns the converter registered for the pair of types, ignoring any
+// parameters.
+func (r *Registry) Lookup(from, to mime.Type) (Converter, bool) {
+	from, to = normalize(from), normalize(to)
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, e := range r.conversions {
+		if e.from == from && e.to == to {
+			return e.converter, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Registry) snapshot() []conversion {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return append([]conversion(nil), r.conversions...)
+}
+
+// Step is a single conversion in a plan.
+type Step struct {
+	From, To  mime.Type
+	Converter Converter
+}
+
+// Plan is a sequence of conversions from one type to another. A plan without
+// steps serves the content as it is, because it is already compatible with
+// the type it is converted to.
+type Plan struct {
+	From, To mime.Type
+	Steps    []Step
+}
+
+// Converts content by running each step of the plan in turn.
+func (p Plan) Convert(dst io.Writer, src io.Reader) error {
+	if len(p.Steps) == 0 {
+		_, err := io.Copy(dst, src)
+		return err
+	}
+	for i, e := range p.Steps {
+		if i == len(p.Steps)-1 {
+			return e.Converter.Convert(dst, src, e.From)
+		}
+		b := &bytes.Buffer{}
+		if err := e.Converter.Convert(b, src, e.From); err != nil {
+			return err
+		}
+		src = b
+	}
+	return nil
+}
+
+// Finds a plan to convert content from one type to another, which may be a
+// wildcard. A converter registered for the pair is preferred, even when the
+// content could be served as it is: converting Markdown to text/plain
+// renders it rather than serving its source. Otherwise, content is served
+// as it is if it is compatible with the type, as determined by
+// mime.Compatible, and failing that the shortest sequence of conversions is
+// planned. Converters apply to content which is compatible with the type
+// they convert from, so application/vnd.acme+json can be converted by a
+// converter from application/json.
+func (r *Registry) Plan(from, to mime.Type) (Plan, error) {
+	plan := Plan{From: from, To: to}
+	target := mime.Options{to}
+	if target.Match(from) {
+		return plan, nil
+	}
+	conversions := r.snapshot()
+	for _, e := range conversions {
+		if target.Match(e.to) && mime.Compatible(from, e.from) {
+			plan.Steps = []Step{{From: from, To: e.to, Converter: e.converter}}
+			return plan, nil
+		}
+	}
+	if mime.Compatible(from, to) {
+		return plan, nil
+	}
+
+	// breadth first search for the shortest sequence of conversions
+	type node struct {
+		t     mime.Type
+		steps []Step
+	}
+	seen := map[mime.Type]struct{}{normalize(from): {}}
+	queue := []node{{t: from}}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range conversions {
+			if _, ok := seen[e.to]; ok || !mime.Compatible(n.t, e.from) {
+				continue
+			}
+			seen[e.to] = struct{}{}
+			steps := append(append([]Step(nil), n.steps...), Step{From: n.t, To: e.to, Converter: e.converter})
+			if mime.Compatible(e.to, to) {
+				plan.Steps = steps
+				return plan, nil
+			}
+			queue = append(queue, node{t: e.to, steps: steps})
+		}
+	}
+	return Plan{}, fmt.Errorf("%w: from %s to %s", ErrNoConversion, from, to)
+}
+
+// Returns the types content of a type can be served as, either as it is or
+// by converting it, nearest first. The type itself is not included.
+func (r *Registry) Targets(from mime.Type) mime.Options {
+	conversions := r.snapshot()
+	var (
+		result mime.Options
+		seen   = map[mime.Type]struct{}{normalize(from): {}}
+		queue  = mime.Options{from}
+	)
+	add := func(t mime.Type) {
+		if _, ok := seen[t]; !ok {
+			seen[t] = struct{}{}
+			result = append(result, t)
+			queue = append(queue, t)
+		}
+	}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, e := range mime.Fallbacks(t) {
+			add(e)
+		}
+		for _, e := range conversions {
+			if mime.Compatible(t, e.from) {
+				add(e.to)
+			}
+		}
+	}
+	return result
+}
+
+// Chooses the type to respond with for content of the given type according
+// to the Accept header of the request, from the type itself and its
+// targets, and returns the plan to convert the content to it. A request
+// without an Accept header is given the type itself.
+func (r *Registry) Negotiate(request *http.Request, from mime.Type) (mime.Type, Plan, error) {
+	availableMediaTypes := make([]accept.MediaType, 0)
+	for _, e := range append(mime.Options{from}, r.Targets(from)...) {
+		m, err := accept.FromType(e)
+		if err != nil {
+			return mime.Invalid, Plan{}, err
+		}
+		availableMediaTypes = append(availableMediaTypes, m)
+	}
+	m, _, err := accept.MatchAcceptableMediaType(request, availableMediaTypes)
+	if err != nil {
+		return mime.Invalid, Plan{}, err
+	}
+	to := m.Mime()
+	plan, err := r.Plan(from, to)
+	if err != nil {
+		return mime.Invalid, Plan{}, err
+	}
+	return to, plan, nil
+}
+
+// Registers a converter with the default registry.
+func Register(from, to mime.Type, c Converter) {
+	DefaultRegistry.Register(from, to, c)
+}
+
+// Finds a plan to convert content from one type to another using the
+// default registry.
+func PlanConversion(from, to mime.Type) (Plan, error) {
+	return DefaultRegistry.Plan(from, to)
+}
+
+// Returns the types content of a type can be served as using the default
+// registry.
+func Targets(from mime.Type) mime.Options {
+	return DefaultRegistry.Targets(from)
+}
+
+// Chooses the type to respond with using the default registry.
+func Negotiate(request *http.Request, from mime.Type) (mime.Type, Plan, error) {
+	return DefaultRegistry.Negotiate(request, from)
+}
+
+// Converts content from one type to another using the default registry.
+func Convert(dst io.Writer, src io.Reader, from, to mime.Type) error {
+	plan, err := DefaultRegistry.Plan(from, to)
+	if err != nil {
+		return err
+	}
+	return plan.Convert(dst, src)
+}
+
+// Decodes text in the charset of its type into UTF-8, removing any byte
+// order mark. Text without a charset is taken to be UTF-8.
+func decode(src io.Reader, from mime.Type) (io.Reader, error) {
+	label := from.Param("charset")
+	if label == "" {
+		label = mime.UTF_8
+	}
+	return charset.NewReader(src, label)
+}
@@ -0,0 +1,164 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestPlan(t *testing.T) {
+	testCases := []struct {
+		name  string
+		from  mime.Type
+		to    mime.Type
+		steps []mime.Type
+		err   error
+	}{
+		{"Same", mime.JSON, mime.JSON, nil, nil},
+		{"Wildcard", mime.JSON, "application/*", nil, nil},
+		{"Parameters", "text/markdown; charset=utf-8", mime.HTML, []mime.Type{mime.HTML}, nil},
+		{"Converter preferred", mime.Markdown, mime.Text, []mime.Type{mime.Text}, nil},
+		{"Compatible", "application/vnd.acme+json", mime.Text, nil, nil},
+		{"Compatible converter", "application/vnd.acme+json", "application/xml", []mime.Type{"application/xml"}, nil},
+		{"Chain", mime.CSV, "application/xml", []mime.Type{mime.JSON, "application/xml"}, nil},
+		{"Chain compatible", mime.CSV, "text/xml", []mime.Type{mime.JSON, "application/xml"}, nil},
+		{"None", "image/png", mime.Text, nil, ErrNoConversion},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			plan, err := PlanConversion(testCase.from, testCase.to)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			var steps []mime.Type
+			for _, e := range plan.Steps {
+				steps = append(steps, e.To)
+			}
+			if mime.Options(steps).String() != mime.Options(testCase.steps).String() {
+				t.Errorf("Unexpected steps %v, expected %v", steps, testCase.steps)
+			}
+			if len(plan.Steps) > 0 && plan.Steps[0].From != testCase.from {
+				t.Errorf("Unexpected type %q, expected %q", plan.Steps[0].From, testCase.from)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	if _, err := r.Plan(mime.JSON, "application/yaml"); !errors.Is(err, ErrNoConversion) {
+		t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, ErrNoConversion)
+	}
+
+	upper := ConverterFunc(func(dst io.Writer, src io.Reader, from mime.Type) error {
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return err
+		}
+		_, err = dst.Write(bytes.ToUpper(data))
+		return err
+	})
+	r.Register("text/*", "text/x-upper; charset=utf-8", upper)
+	if _, ok := r.Lookup("text/*", "TEXT/X-UPPER"); !ok {
+		t.Errorf("Expected the converter to be registered")
+	}
+
+	plan, err := r.Plan(mime.Text, "text/x-upper")
+	if err != nil {
+		t.Fatalf("Unexpected error \"%v\"", err)
+	}
+	b := &bytes.Buffer{}
+	if err := plan.Convert(b, strings.NewReader("hello")); err != nil {
+		t.Fatalf("Unexpected error \"%v\"", err)
+	}
+	if b.String() != "HELLO" {
+		t.Errorf("Unexpected content %q, expected %q", b.String(), "HELLO")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering a conversion to a wildcard to panic")
+		}
+	}()
+	r.Register(mime.Text, "text/*", upper)
+}
+
+func TestTargets(t *testing.T) {
+	testCases := []struct {
+		from   mime.Type
+		result mime.Options
+	}{
+		{mime.Markdown, mime.Options{mime.Text, mime.HTML}},
+		{mime.CSV, mime.Options{mime.Text, mime.JSON, "application/xml", "text/xml"}},
+		{"application/vnd.acme+json", mime.Options{mime.JSON, mime.Text, "application/xml", "text/xml"}},
+		{"image/png", nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.from.String(), func(t *testing.T) {
+			if result := Targets(testCase.from); result.String() != testCase.result.String() {
+				t.Errorf("Unexpected targets %q, expected %q", result, testCase.result)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		accept string
+		from   mime.Type
+		result mime.Type
+		steps  int
+	}{
+		{"No header", "", mime.Markdown, mime.Markdown, 0},
+		{"Same", "text/markdown", mime.Markdown, mime.Markdown, 0},
+		{"Convert", "text/html, text/markdown;q=0.5", mime.Markdown, mime.HTML, 1},
+		{"Text", "text/plain", mime.Markdown, mime.Text, 1},
+		{"Chain", "application/xml", mime.CSV, "application/xml", 2},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if testCase.accept != "" {
+				req.Header.Set("Accept", testCase.accept)
+			}
+			result, plan, err := Negotiate(req, testCase.from)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%v\"", err)
+			}
+			if result != testCase.result {
+				t.Errorf("Unexpected type %q, expected %q", result, testCase.result)
+			}
+			if len(plan.Steps) != testCase.steps {
+				t.Errorf("Unexpected number of steps %d, expected %d", len(plan.Steps), testCase.steps)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "image/png")
+	if _, _, err := Negotiate(req, mime.Markdown); err == nil {
+		t.Errorf("Expected an error when no target is acceptable")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	b := &bytes.Buffer{}
+	err := Convert(b, strings.NewReader("name,size\r\nbig,10\r\n"), "text/csv; header=present", "application/xml")
+	if err != nil {
+		t.Fatalf("Unexpected error \"%v\"", err)
+	}
+	expect := xml.Header + "<root><item><name>big</name><size>10</size></item></root>\n"
+	if b.String() != expect {
+		t.Errorf("Unexpected content %q, expected %q", b.String(), expect)
+	}
+}
@@ -0,0 +1,97 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	mime "github.com/bww/go-mime/v1"
+	mimecsv "github.com/bww/go-mime/v1/csv"
+)
+
+// Converts CSV to a JSON array. When the header parameter of the type is
+// present each record is converted to an object whose keys are the fields
+// of the header, in order; otherwise each record is converted to an array of
+// strings. The dialect is taken from the parameters of the type.
+func csvToJSON(dst io.Writer, src io.Reader, from mime.Type) error {
+	d, err := mimecsv.FromType(from)
+	if err != nil {
+		return err
+	}
+	r, err := mimecsv.NewReader(src, from)
+	if err != nil {
+		return err
+	}
+	r.FieldsPerRecord = -1
+
+	var header []string
+	if d.Header {
+		header, err = r.Read()
+		if errors.Is(err, io.EOF) {
+			_, err = io.WriteString(dst, "[]\n")
+			return err
+		} else if err != nil {
+			return err
+		}
+	}
+
+	w := bufio.NewWriter(dst)
+	w.WriteByte('[')
+	for n := 0; ; n++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if n > 0 {
+			w.WriteByte(',')
+		}
+		if header == nil {
+			w.WriteByte('[')
+			for i, e := range record {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				writeJSONString(w, e)
+			}
+			w.WriteByte(']')
+			continue
+		}
+		w.WriteByte('{')
+		for i, e := range record {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			key := fieldName(header, i)
+			writeJSONString(w, key)
+			w.WriteByte(':')
+			writeJSONString(w, e)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString("]\n")
+	return w.Flush()
+}
+
+// Returns the name of a field, which is the corresponding field of the
+// header, or its position if the record is longer than the header.
+func fieldName(header []string, i int) string {
+	if i < len(header) {
+		return header[i]
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// Writes a string as JSON, without escaping the characters json.Marshal
+// escapes for embedding in HTML.
+func writeJSONString(w *bufio.Writer, s string) {
+	b := &bytes.Buffer{}
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+	e.Encode(s)
+	w.Write(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
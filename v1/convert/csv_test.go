@@ -0,0 +1,38 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestCSVToJSON(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		from   mime.Type
+		result string
+	}{
+		{"No header", "a,b\r\n1,2\r\n", mime.CSV, `[["a","b"],["1","2"]]`},
+		{"Header", "b,a\r\n1,2\r\n3,4,5\r\n", "text/csv; header=present", `[{"b":"1","a":"2"},{"b":"3","a":"4","3":"5"}]`},
+		{"Header only", "a,b\r\n", "text/csv; header=present", `[]`},
+		{"Empty", "", "text/csv; header=present", `[]`},
+		{"Dialect", "a;\"<b>\"\n", `text/csv; delimiter=";"; lineterminator=lf`, `[["a","<b>"]]`},
+		{"TSV", "a\tb\n", "text/tab-separated-values", `[["a","b"]]`},
+		{"Charset", "caf\xe9\r\n", "text/csv; charset=windows-1252", `[["café"]]`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := csvToJSON(b, strings.NewReader(testCase.input), testCase.from); err != nil {
+				t.Fatalf("Unexpected error \"%v\"", err)
+			}
+			if expect := testCase.result + "\n"; b.String() != expect {
+				t.Errorf("Unexpected content %q, expected %q", b.String(), expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package convert
+
+import (
+	"html"
+	"io"
+	"strings"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// Elements which start a new line in text.
+var htmlBlocks = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true,
+	"dd": true, "details": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "html": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true,
+	"table": true, "tbody": true, "tfoot": true, "thead": true, "tr": true, "ul": true,
+}
+
+// Elements whose content is not text.
+var htmlHidden = map[string]bool{
+	"head": true, "noscript": true, "script": true, "style": true, "template": true,
+	"title": true,
+}
+
+// Extracts the text of an HTML document. Markup, comments and the content of
+// elements which are not displayed, like scripts, are removed; block
+// elements are separated by new lines, list items are marked with a dash and
+// table cells are separated by tabs. Whitespace is collapsed, except within
+// pre elements.
+func htmlToText(dst io.Writer, src io.Reader, from mime.Type) error {
+	r, err := decode(src, from)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	t := &textWriter{}
+	s := string(data)
+	var hidden string // the element whose content is being skipped
+	pre := 0
+	for len(s) > 0 {
+		x := strings.IndexByte(s, '<')
+		if x < 0 {
+			x = len(s)
+		}
+		if hidden == "" {
+			t.text(html.UnescapeString(s[:x]), pre > 0)
+		}
+		s = s[x:]
+		if s == "" {
+			break
+		}
+
+		if strings.HasPrefix(s, "<!--") {
+			if end := strings.Index(s[4:], "-->"); end >= 0 {
+				s = s[4+end+3:]
+			} else {
+				s = ""
+			}
+			continue
+		}
+		name, closing, n := parseTag(s)
+		if n == 0 {
+			if hidden == "" {
+				t.text("<", pre > 0)
+			}
+			s = s[1:]
+			continue
+		}
+		s = s[n:]
+
+		if hidden != "" {
+			if closing && name == hidden {
+				hidden = ""
+			}
+			continue
+		}
+		switch {
+		case htmlHidden[name] && !closing:
+			hidden = name
+		case name == "br":
+			t.newline()
+		case name == "pre":
+			t.block()
+			if closing {
+				pre--
+			} else {
+				pre++
+			}
+		case name == "li" && !closing:
+			t.block()
+			t.text("- ", true)
+		case (name == "td" || name == "th") && !closing:
+			t.cell()
+		case htmlBlocks[name]:
+			t.block()
+		}
+	}
+
+	_, err = io.WriteString(dst, t.String())
+	return err
+}
+
+// Parses the tag at the start of the text and returns its lower case name,
+// whether it closes an element and its length. The length is zero if the
+// text does not start with a tag. Declarations and processing instructions
+// are returned with an empty name.
+func parseTag(s string) (string, bool, int) {
+	if len(s) < 2 {
+		return "", false, 0
+	}
+	i, closing := 1, false
+	switch c := s[1]; {
+	case c == '/':
+		closing = true
+		i++
+	case c == '!' || c == '?':
+		if end := strings.IndexByte(s, '>'); end >= 0 {
+			return "", false, end + 1
+		}
+		return "", false, 0
+	}
+	start := i
+	for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z' || s[i] >= '0' && s[i] <= '9' || s[i] == '-') {
+		i++
+	}
+	if i == start {
+		return "", false, 0
+	}
+	name := strings.ToLower(s[start:i])
+
+	// find the end of the tag, skipping quoted attribute values
+	var quote byte
+	for ; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return name, closing, i + 1
+		}
+	}
+	return name, closing, len(s)
+}
+
+// Accumulates text, collapsing whitespace and blank lines.
+type textWriter struct {
+	strings.Builder
+	space  bool // whitespace is pending
+	breaks int  // line breaks are pending
+	cells  int  // cells on the current line
+}
+
+func (t *textWriter) text(s string, preformatted bool) {
+	for _, c := range s {
+		switch {
+		case preformatted && c == '\n':
+			t.flush()
+			t.WriteByte('\n')
+		case preformatted:
+			t.flush()
+			t.WriteRune(c)
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			t.space = true
+		default:
+			t.flush()
+			t.WriteRune(c)
+		}
+	}
+}
+
+func (t *textWriter) flush() {
+	if t.Len() > 0 {
+		if t.breaks > 0 {
+			t.WriteString(strings.Repeat("\n", t.breaks))
+		} else if t.space {
+			t.WriteByte(' ')
+		}
+	}
+	t.space, t.breaks = false, 0
+}
+
+func (t *textWriter) newline() {
+	t.flush()
+	t.WriteByte('\n')
+	t.cells = 0
+}
+
+func (t *textWriter) block() {
+	if t.breaks < 1 {
+		t.breaks = 1
+	}
+	t.space = false
+	t.cells = 0
+}
+
+func (t *textWriter) cell() {
+	if t.cells > 0 {
+		t.space, t.breaks = false, 0
+		t.WriteByte('\t')
+	}
+	t.cells++
+}
+
+func (t *textWriter) String() string {
+	s := strings.TrimSpace(t.Builder.String())
+	if s == "" {
+		return ""
+	}
+	return s + "\n"
+}
@@ -0,0 +1,43 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestHTMLToText(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		from   mime.Type
+		result string
+	}{
+		{"Empty", "", mime.HTML, ""},
+		{"Whitespace", "<p>Some   text\n  here</p>", mime.HTML, "Some text here\n"},
+		{"Blocks", "<h1>Title</h1><p>One<br>two</p><div>Three</div>", mime.HTML, "Title\nOne\ntwo\nThree\n"},
+		{"Inline", "<p>Some <b>bold</b> <a href=\"x\">link</a></p>", mime.HTML, "Some bold link\n"},
+		{"Entities", "<p>Fish &amp; chips &lt;3 &eacute;</p>", mime.HTML, "Fish & chips <3 é\n"},
+		{"Hidden", "<html><head><title>T</title><style>p{}</style></head><body><script>if (a < b) {}</script><!-- <p>no</p> -->Text</body></html>", mime.HTML, "Text\n"},
+		{"List", "<ul><li>one</li><li>two</li></ul>", mime.HTML, "- one\n- two\n"},
+		{"Table", "<table><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>2</td></tr></table>", mime.HTML, "a\tb\n1\t2\n"},
+		{"Preformatted", "<p>code:</p><pre>  a\n    b</pre>", mime.HTML, "code:\n  a\n    b\n"},
+		{"Attributes", `<p title="a > b">Text</p>`, mime.HTML, "Text\n"},
+		{"Less than", "a < b", mime.HTML, "a < b\n"},
+		{"Charset", "<p>caf\xe9</p>", "text/html; charset=iso-8859-1", "café\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := htmlToText(b, strings.NewReader(testCase.input), testCase.from); err != nil {
+				t.Fatalf("Unexpected error \"%v\"", err)
+			}
+			if b.String() != testCase.result {
+				t.Errorf("Unexpected content %q, expected %q", b.String(), testCase.result)
+			}
+		})
+	}
+}
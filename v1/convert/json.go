@@ -0,0 +1,250 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/charset"
+)
+
+// The element which contains a JSON document converted to XML.
+const xmlRoot = "root"
+
+// The element which contains each item of an array which is not the value
+// of a member of an object.
+const xmlItem = "item"
+
+// The element which contains the value of a member whose name is not a valid
+// XML name; the name is given by its name attribute.
+const xmlEntry = "entry"
+
+// Converts JSON to XML. The document is contained by a root element, and the
+// value of each member of an object is contained by an element named for
+// the member, in order. An array which is the value of a member is
+// represented by repeating the member's element for each item; other arrays
+// contain an item element for each item. Null is represented by an empty
+// element.
+func jsonToXML(dst io.Writer, src io.Reader, from mime.Type) error {
+	r, err := decode(src, from)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	w := bufio.NewWriter(dst)
+	w.WriteString(xml.Header)
+	if err := writeXMLElement(w, d, xmlRoot, true); err != nil {
+		return err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return fmt.Errorf("%w: content after the JSON document", ErrInvalidContent)
+	}
+	w.WriteByte('\n')
+	return w.Flush()
+}
+
+// Reads a JSON value from the decoder and writes it as an element. Unless the
+// value is an item of an array, an array is written as a repeated element.
+func writeXMLElement(w *bufio.Writer, d *json.Decoder, name string, item bool) error {
+	tok, err := d.Token()
+	if err != nil {
+		return err
+	}
+	open, end := "<"+name, "</"+name+">"
+	if !isXMLName(name) {
+		b := &strings.Builder{}
+		xml.EscapeText(b, []byte(name))
+		open, end = "<"+xmlEntry+` name="`+b.String()+`"`, "</"+xmlEntry+">"
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '[' && !item {
+			for d.More() {
+				if err := writeXMLElement(w, d, name, true); err != nil {
+					return err
+				}
+			}
+			_, err := d.Token()
+			return err
+		}
+		w.WriteString(open + ">")
+		for d.More() {
+			child := xmlItem
+			if v == '{' {
+				tok, err := d.Token()
+				if err != nil {
+					return err
+				}
+				child = tok.(string)
+			}
+			if err := writeXMLElement(w, d, child, v == '['); err != nil {
+				return err
+			}
+		}
+		if _, err := d.Token(); err != nil {
+			return err
+		}
+		w.WriteString(end)
+	case nil:
+		w.WriteString(open + "/>")
+	default:
+		w.WriteString(open + ">")
+		xml.EscapeText(w, []byte(fmt.Sprint(v)))
+		w.WriteString(end)
+	}
+	return nil
+}
+
+// Reports whether the string is a name which may be used for an element.
+// Names are restricted to ASCII letters, digits, hyphens, underscores and
+// periods, and names starting with "xml", which are reserved, are excluded.
+func isXMLName(s string) bool {
+	if s == "" || len(s) >= 3 && strings.EqualFold(s[:3], "xml") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// An XML element, with its attributes, its children grouped by name in the
+// order each name first appears, and its text.
+type xmlNode struct {
+	attrs    []xml.Attr
+	names    []string
+	children map[string][]*xmlNode
+	text     strings.Builder
+}
+
+func (n *xmlNode) add(name string, child *xmlNode) {
+	if n.children == nil {
+		n.children = map[string][]*xmlNode{}
+	}
+	if _, ok := n.children[name]; !ok {
+		n.names = append(n.names, name)
+	}
+	n.children[name] = append(n.children[name], child)
+}
+
+// Converts XML to JSON. The content of the root element is converted, so
+// that JSON converted to XML is converted back to the same structure,
+// although all values are strings. An element with only text is converted
+// to a string, and other elements to objects: attributes are members named
+// with a leading "@", children are members named for the element, and text
+// is a member named "#text". Elements which are repeated are converted to
+// arrays.
+func xmlToJSON(dst io.Writer, src io.Reader, from mime.Type) error {
+	r, err := decode(src, from)
+	if err != nil {
+		return err
+	}
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if from.Param("charset") != "" {
+			return input, nil // the charset of the type takes precedence
+		}
+		return charset.NewReader(input, label)
+	}
+
+	var (
+		root  *xmlNode
+		stack []*xmlNode
+	)
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		switch v := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{}
+			name := v.Name.Local
+			for _, a := range v.Attr {
+				if v.Name.Local == xmlEntry && a.Name.Local == "name" && len(v.Attr) == 1 {
+					name = a.Value
+				} else {
+					n.attrs = append(n.attrs, a)
+				}
+			}
+			if len(stack) > 0 {
+				stack[len(stack)-1].add(name, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(v)
+			}
+		}
+	}
+	if root == nil {
+		return fmt.Errorf("%w: no XML root element", ErrInvalidContent)
+	}
+
+	w := bufio.NewWriter(dst)
+	writeJSONNode(w, root)
+	w.WriteByte('\n')
+	return w.Flush()
+}
+
+func writeJSONNode(w *bufio.Writer, n *xmlNode) {
+	text := strings.TrimSpace(n.text.String())
+	if len(n.attrs) == 0 && len(n.names) == 0 {
+		writeJSONString(w, text)
+		return
+	}
+	w.WriteByte('{')
+	first := true
+	member := func(name string) {
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		writeJSONString(w, name)
+		w.WriteByte(':')
+	}
+	for _, e := range n.attrs {
+		member("@" + e.Name.Local)
+		writeJSONString(w, e.Value)
+	}
+	for _, e := range n.names {
+		member(e)
+		children := n.children[e]
+		if len(children) == 1 {
+			writeJSONNode(w, children[0])
+			continue
+		}
+		w.WriteByte('[')
+		for i, c := range children {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeJSONNode(w, c)
+		}
+		w.WriteByte(']')
+	}
+	if text != "" {
+		member("#text")
+		writeJSONString(w, text)
+	}
+	w.WriteByte('}')
+}
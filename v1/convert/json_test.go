@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestJSONToXML(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		result string
+		err    error
+	}{
+		{"Object", `{"b":1,"a":"x"}`, "<root><b>1</b><a>x</a></root>", nil},
+		{"Scalar", `"a & b"`, "<root>a &amp; b</root>", nil},
+		{"Array", `[1,true]`, "<root><item>1</item><item>true</item></root>", nil},
+		{"Member array", `{"a":[1,2],"b":[[3],[]]}`, "<root><a>1</a><a>2</a><b><item>3</item></b><b></b></root>", nil},
+		{"Null", `{"a":null}`, "<root><a/></root>", nil},
+		{"Names", `{"a b":1,"xmlns":2,"1":3}`, `<root><entry name="a b">1</entry><entry name="xmlns">2</entry><entry name="1">3</entry></root>`, nil},
+		{"Numbers", `{"n":1.50e3}`, "<root><n>1.50e3</n></root>", nil},
+		{"Invalid", `{"a":}`, "", nil},
+		{"Trailing", `{} {}`, "", ErrInvalidContent},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			err := jsonToXML(b, strings.NewReader(testCase.input), mime.JSON)
+			if testCase.result == "" {
+				if err == nil || (testCase.err != nil && !errors.Is(err, testCase.err)) {
+					t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error \"%v\"", err)
+			}
+			if expect := xml.Header + testCase.result + "\n"; b.String() != expect {
+				t.Errorf("Unexpected content %q, expected %q", b.String(), expect)
+			}
+		})
+	}
+}
+
+func TestXMLToJSON(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		from   mime.Type
+		result string
+	}{
+		{"Text", "<root>a &amp; b</root>", "application/xml", `"a & b"`},
+		{"Children", "<root><b>1</b><a>x</a><b>2</b></root>", "application/xml", `{"b":["1","2"],"a":"x"}`},
+		{"Attributes", `<root id="1"><a lang="en">hi</a>text</root>`, "application/xml", `{"@id":"1","a":{"@lang":"en","#text":"hi"},"#text":"text"}`},
+		{"Entries", `<root><entry name="a b">1</entry></root>`, "application/xml", `{"a b":"1"}`},
+		{"Declared charset", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><root>caf\xe9</root>", "application/xml", `"café"`},
+		{"Type charset", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><root>café</root>", "application/xml; charset=utf-8", `"café"`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := xmlToJSON(b, strings.NewReader(testCase.input), testCase.from); err != nil {
+				t.Fatalf("Unexpected error \"%v\"", err)
+			}
+			if expect := testCase.result + "\n"; b.String() != expect {
+				t.Errorf("Unexpected content %q, expected %q", b.String(), expect)
+			}
+		})
+	}
+
+	if err := xmlToJSON(&bytes.Buffer{}, strings.NewReader(""), "application/xml"); !errors.Is(err, ErrInvalidContent) {
+		t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, ErrInvalidContent)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	input := `{"name":"go-mime","tags":["a","b"],"owner":{"login":"bww"}}`
+	x, j := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := jsonToXML(x, strings.NewReader(input), mime.JSON); err != nil {
+		t.Fatalf("Unexpected error \"%v\"", err)
+	}
+	if err := xmlToJSON(j, x, "application/xml"); err != nil {
+		t.Fatalf("Unexpected error \"%v\"", err)
+	}
+	if j.String() != input+"\n" {
+		t.Errorf("Unexpected content %q, expected %q", j.String(), input+"\n")
+	}
+}
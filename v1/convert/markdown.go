@@ -0,0 +1,228 @@
+package convert
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// Parses and renders CommonMark, the common subset of Markdown variants. Raw
+// HTML is omitted rather than passed through, and links and images with
+// schemes other than http, https and mailto are reduced to their text, so
+// that rendering content from untrusted sources is safe.
+var markdown = goldmark.New(
+	goldmark.WithParserOptions(
+		parser.WithASTTransformers(util.Prioritized(safeLinks{}, 100)),
+	),
+)
+
+func readMarkdown(src io.Reader, from mime.Type) ([]byte, error) {
+	r, err := decode(src, from)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// Renders Markdown as an HTML fragment.
+func markdownToHTML(dst io.Writer, src io.Reader, from mime.Type) error {
+	source, err := readMarkdown(src, from)
+	if err != nil {
+		return err
+	}
+	return markdown.Convert(source, dst)
+}
+
+// Renders Markdown as plain text, without its markup. Links are followed by
+// their destination in parentheses, list items are marked with a dash or
+// their number, and quotes and code blocks are indented.
+func markdownToText(dst io.Writer, src io.Reader, from mime.Type) error {
+	source, err := readMarkdown(src, from)
+	if err != nil {
+		return err
+	}
+	doc := markdown.Parser().Parse(text.NewReader(source))
+	t := textRenderer{source: source}
+	_, err = io.WriteString(dst, strings.TrimRight(t.blocks(doc, false), "\n")+"\n")
+	return err
+}
+
+// Replaces links and images whose destinations are not safe with their
+// content, and autolinks with their text.
+type safeLinks struct{}
+
+func (safeLinks) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var unsafe []ast.Node
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch e := n.(type) {
+		case *ast.Link:
+			if safeURL(string(e.Destination)) == "" {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.Image:
+			if safeURL(string(e.Destination)) == "" {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.AutoLink:
+			if safeURL(string(e.URL(source))) == "" {
+				unsafe = append(unsafe, n)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range unsafe {
+		parent := n.Parent()
+		if e, ok := n.(*ast.AutoLink); ok {
+			parent.ReplaceChild(parent, n, ast.NewString(e.Label(source)))
+			continue
+		}
+		for c := n.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			parent.InsertBefore(parent, n, c)
+			c = next
+		}
+		parent.RemoveChild(parent, n)
+	}
+}
+
+// Returns the URL if it is relative or its scheme is http, https or mailto,
+// and otherwise an empty string.
+func safeURL(s string) string {
+	x := strings.IndexAny(s, ":/?#")
+	if x < 0 || s[x] != ':' {
+		return s
+	}
+	switch strings.ToLower(s[:x]) {
+	case "http", "https", "mailto":
+		return s
+	}
+	return ""
+}
+
+// Renders a parsed Markdown document as text.
+type textRenderer struct {
+	source []byte
+}
+
+// Returns the text of the blocks in a container, separated by blank lines
+// unless they are tight, like the blocks of an item in a tight list.
+func (t textRenderer) blocks(n ast.Node, tight bool) string {
+	b := &strings.Builder{}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		s := t.block(c)
+		if s == "" {
+			continue
+		}
+		if b.Len() > 0 && !tight {
+			b.WriteString("\n")
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// Returns the text of a block, which ends with a new line. Raw HTML produces
+// no text.
+func (t textRenderer) block(n ast.Node) string {
+	switch e := n.(type) {
+	case *ast.Paragraph, *ast.TextBlock, *ast.Heading:
+		b := &strings.Builder{}
+		t.inline(b, n)
+		lines := strings.Split(b.String(), "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimSpace(l)
+		}
+		return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		b := &strings.Builder{}
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			seg := lines.At(i)
+			b.Write(seg.Value(t.source))
+		}
+		return indentLines(b.String(), "    ", "    ")
+	case *ast.Blockquote:
+		return indentLines(t.blocks(n, false), "  ", "  ")
+	case *ast.List:
+		b := &strings.Builder{}
+		number := e.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "- "
+			if e.IsOrdered() {
+				marker = strconv.Itoa(number) + ". "
+				number++
+			}
+			if b.Len() > 0 && !e.IsTight {
+				b.WriteString("\n")
+			}
+			b.WriteString(indentLines(t.blocks(item, e.IsTight), marker, strings.Repeat(" ", len(marker))))
+		}
+		return b.String()
+	case *ast.ThematicBreak:
+		return "----\n"
+	}
+	return ""
+}
+
+// Writes the text of the inline content of a node. Links are followed by
+// their destination unless it is the same as their text.
+func (t textRenderer) inline(b *strings.Builder, n ast.Node) {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch e := c.(type) {
+		case *ast.Text:
+			v := e.Segment.Value(t.source)
+			if !e.IsRaw() {
+				v = util.ResolveEntityNames(util.ResolveNumericReferences(util.UnescapePunctuations(v)))
+			}
+			b.Write(v)
+			if e.SoftLineBreak() || e.HardLineBreak() {
+				b.WriteString("\n")
+			}
+		case *ast.String:
+			b.Write(e.Value)
+		case *ast.AutoLink:
+			b.Write(e.Label(t.source))
+		case *ast.Link:
+			label := &strings.Builder{}
+			t.inline(label, c)
+			b.WriteString(label.String())
+			if dest := string(e.Destination); dest != label.String() {
+				b.WriteString(" (" + dest + ")")
+			}
+		case *ast.RawHTML:
+		default:
+			t.inline(b, c)
+		}
+	}
+}
+
+// Indents the lines of the text, the first with the first prefix and the
+// rest with the other, leaving blank lines empty.
+func indentLines(s, first, rest string) string {
+	b := &strings.Builder{}
+	for i, l := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		if l != "" {
+			if i == 0 {
+				b.WriteString(first)
+			} else {
+				b.WriteString(rest)
+			}
+			b.WriteString(l)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
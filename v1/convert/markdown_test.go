@@ -0,0 +1,86 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		result string
+	}{
+		{"Heading", "# Title #\n", "<h1>Title</h1>\n"},
+		{"Setext", "Title\n---\n", "<h2>Title</h2>\n"},
+		{"Paragraphs", "One\ntwo\n\nThree\n", "<p>One\ntwo</p>\n<p>Three</p>\n"},
+		{"Emphasis", "*em* __strong__ snake_case_name\n", "<p><em>em</em> <strong>strong</strong> snake_case_name</p>\n"},
+		{"Code span", "`a <b>` and ``c`d``\n", "<p><code>a &lt;b&gt;</code> and <code>c`d</code></p>\n"},
+		{"Strong emphasis", "***both*** **strong *em***\n", "<p><em><strong>both</strong></em> <strong>strong <em>em</em></strong></p>\n"},
+		{"Escapes", `\*not em\* &amp; &` + "\n", "<p>*not em* &amp; &amp;</p>\n"},
+		{"Raw HTML", "one <i>two</i>\n\n<script>alert(1)</script>\n", "<p>one <!-- raw HTML omitted -->two<!-- raw HTML omitted --></p>\n<!-- raw HTML omitted -->\n"},
+		{"Hard break", "one  \ntwo\n", "<p>one<br>\ntwo</p>\n"},
+		{"Link", `[the *site*](https://example.com/a_(b) "Title")` + "\n", `<p><a href="https://example.com/a_(b)" title="Title">the <em>site</em></a></p>` + "\n"},
+		{"Reference links", "[full][1], [collapsed][] and [Shortcut]\n\n[1]: https://example.com\n[collapsed]: /c\n[shortcut]: /s \"S\"\n", `<p><a href="https://example.com">full</a>, <a href="/c">collapsed</a> and <a href="/s" title="S">Shortcut</a></p>` + "\n"},
+		{"Unsafe link", "[click](javascript:alert(1))\n", "<p>click</p>\n"},
+		{"Unsafe autolink", "<javascript:alert(1)>\n", "<p>javascript:alert(1)</p>\n"},
+		{"Unsafe image", "![a cat](data:text/html,x)\n", "<p>a cat</p>\n"},
+		{"Image", "![a cat](cat.png)\n", `<p><img src="cat.png" alt="a cat"></p>` + "\n"},
+		{"Autolinks", "<https://example.com> <me@example.com>\n", `<p><a href="https://example.com">https://example.com</a> <a href="mailto:me@example.com">me@example.com</a></p>` + "\n"},
+		{"List", "- one\n- two\n  more\n- three\n", "<ul>\n<li>one</li>\n<li>two\nmore</li>\n<li>three</li>\n</ul>\n"},
+		{"Loose list", "- one\n\n- two\n", "<ul>\n<li>\n<p>one</p>\n</li>\n<li>\n<p>two</p>\n</li>\n</ul>\n"},
+		{"Nested list", "- a\n  - b\n    1. c\n- d\n", "<ul>\n<li>a\n<ul>\n<li>b\n<ol>\n<li>c</li>\n</ol>\n</li>\n</ul>\n</li>\n<li>d</li>\n</ul>\n"},
+		{"List continuation", "- one\n\n  more\n- two\n", "<ul>\n<li>\n<p>one</p>\n<p>more</p>\n</li>\n<li>\n<p>two</p>\n</li>\n</ul>\n"},
+		{"Ordered list", "3. three\n4. four\n", "<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n"},
+		{"Quote", "> # Quoted\n> text\n", "<blockquote>\n<h1>Quoted</h1>\n<p>text</p>\n</blockquote>\n"},
+		{"Fenced code", "```go\nif a < b {\n\n}\n```\n", "<pre><code class=\"language-go\">if a &lt; b {\n\n}\n</code></pre>\n"},
+		{"Indented code", "    one\n\n    two\n", "<pre><code>one\n\ntwo\n</code></pre>\n"},
+		{"Rule", "one\n\n***\n", "<p>one</p>\n<hr>\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := markdownToHTML(b, strings.NewReader(testCase.input), mime.Markdown); err != nil {
+				t.Fatalf("Unexpected error \"%v\"", err)
+			}
+			if b.String() != testCase.result {
+				t.Errorf("Unexpected content %q, expected %q", b.String(), testCase.result)
+			}
+		})
+	}
+}
+
+func TestMarkdownToText(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		result string
+	}{
+		{
+			"Document",
+			"# Title\n\nSome *em* and [a link](https://example.com).\n\n- one\n- two\n  more\n\n> quoted\n\n```\ncode\n```\n",
+			"Title\n\nSome em and a link (https://example.com).\n\n- one\n- two\n  more\n\n  quoted\n\n    code\n",
+		},
+		{"Strong emphasis", "***both***\n", "both\n"},
+		{"Nested list", "1. a\n   - b\n     - c\n2. d\n", "1. a\n   - b\n     - c\n2. d\n"},
+		{"List continuation", "- one\n\n  more\n- two\n", "- one\n\n  more\n\n- two\n"},
+		{"Reference links", "[site][1] and <https://example.com>\n\n[1]: https://example.com\n", "site (https://example.com) and https://example.com\n"},
+		{"Unsafe link", "[click](javascript:alert(1)) <b>bold</b>\n", "click bold\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := markdownToText(b, strings.NewReader(testCase.input), mime.Markdown); err != nil {
+				t.Fatalf("Unexpected error \"%v\"", err)
+			}
+			if b.String() != testCase.result {
+				t.Errorf("Unexpected content %q, expected %q", b.String(), testCase.result)
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+// Package csv detects the dialect of delimited text and configures
+// encoding/csv from the parameters of a text/csv type.
+//
+// RFC 4180 defines the header parameter of text/csv. The delimiter,
+// quotechar and lineterminator parameters, named after the dialect
+// properties of Python's csv module, describe files which do not follow the
+// RFC, like the semicolon delimited files spreadsheets export in locales
+// which use the comma as a decimal separator.
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/charset"
+)
+
+var (
+	ErrNotCSV             = errors.New("type is not CSV")
+	ErrInvalidDialect     = errors.New("invalid CSV dialect")
+	ErrUnsupportedDialect = errors.New("unsupported CSV dialect")
+)
+
+// TSV is the type of tab separated values, which is read as CSV delimited by
+// tabs.
+const TSV = mime.Type("text/tab-separated-values")
+
+// Parameters of text/csv which describe its dialect.
+const (
+	ParamHeader         = "header"
+	ParamDelimiter      = "delimiter"
+	ParamQuote          = "quotechar"
+	ParamLineTerminator = "lineterminator"
+)
+
+// Values of the header parameter, per RFC 4180, section 3.
+const (
+	HeaderPresent = "present"
+	HeaderAbsent  = "absent"
+)
+
+// Names of line terminators in the lineterminator parameter.
+var lineTerminators = map[string]string{
+	"crlf": "\r\n",
+	"lf":   "\n",
+	"cr":   "\r",
+}
+
+// Names of delimiters which are whitespace, and so are given by name in the
+// delimiter parameter.
+var delimiterNames = map[string]rune{
+	"tab":   '\t',
+	"space": ' ',
+}
+
+// Dialect describes the format of delimited text.
+type Dialect struct {
+	Delimiter      rune
+	Quote          rune
+	Header         bool   // the first record names the fields
+	LineTerminator string // "\r\n", "\n" or "\r"
+}
+
+// RFC4180 is the dialect RFC 4180 defines, without a header.
+var RFC4180 = Dialect{Delimiter: ',', Quote: '"', LineTerminator: "\r\n"}
+
+// Returns the text/csv type with the parameters which describe the dialect.
+// The header parameter is always set; the others are set when they differ
+// from RFC 4180.
+func (d Dialect) Type() mime.Type {
+	t := mime.CSV
+	if d.Header {
+		t = t.WithParam(ParamHeader, HeaderPresent)
+	} else {
+		t = t.WithParam(ParamHeader, HeaderAbsent)
+	}
+	if d.Delimiter != RFC4180.Delimiter {
+		t = t.WithParam(ParamDelimiter, formatDelimiter(d.Delimiter))
+	}
+	if d.Quote != RFC4180.Quote {
+		t = t.WithParam(ParamQuote, string(d.Quote))
+	}
+	if d.LineTerminator != RFC4180.LineTerminator {
+		for k, v := range lineTerminators {
+			if v == d.LineTerminator {
+				t = t.WithParam(ParamLineTerminator, k)
+			}
+		}
+	}
+	return t
+}
+
+func formatDelimiter(r rune) string {
+	for k, v := range delimiterNames {
+		if v == r {
+			return k
+		}
+	}
+	return string(r)
+}
+
+// Returns the dialect described by the parameters of a text/csv or
+// text/tab-separated-values type. Parameters which are not given take their
+// values from RFC 4180, except that the delimiter of TSV is a tab.
+func FromType(t mime.Type) (Dialect, error) {
+	_, params, err := mime.Parse(t.String())
+	if err != nil {
+		return Dialect{}, fmt.Errorf("%w: %v", ErrInvalidDialect, err)
+	}
+	d := RFC4180
+	switch {
+	case t.Matches(mime.CSV):
+	case t.Matches(TSV):
+		d.Delimiter = '\t'
+	default:
+		return Dialect{}, ErrNotCSV
+	}
+
+	if v, ok := params[ParamHeader]; ok {
+		switch strings.ToLower(v) {
+		case HeaderPresent:
+			d.Header = true
+		case HeaderAbsent:
+			d.Header = false
+		default:
+			return Dialect{}, fmt.Errorf("%w: header %q", ErrInvalidDialect, v)
+		}
+	}
+	if v, ok := params[ParamDelimiter]; ok {
+		if r, ok := delimiterNames[strings.ToLower(v)]; ok {
+			d.Delimiter = r
+		} else if r, ok := singleRune(v); ok {
+			d.Delimiter = r
+		} else {
+			return Dialect{}, fmt.Errorf("%w: delimiter %q", ErrInvalidDialect, v)
+		}
+	}
+	if v, ok := params[ParamQuote]; ok {
+		if r, ok := singleRune(v); ok {
+			d.Quote = r
+		} else {
+			return Dialect{}, fmt.Errorf("%w: quotechar %q", ErrInvalidDialect, v)
+		}
+	}
+	if v, ok := params[ParamLineTerminator]; ok {
+		if s, ok := lineTerminators[strings.ToLower(v)]; ok {
+			d.LineTerminator = s
+		} else {
+			return Dialect{}, fmt.Errorf("%w: lineterminator %q", ErrInvalidDialect, v)
+		}
+	}
+
+	if d.Delimiter == d.Quote || d.Delimiter == '\r' || d.Delimiter == '\n' || d.Quote == '\r' || d.Quote == '\n' {
+		return Dialect{}, ErrInvalidDialect
+	}
+	return d, nil
+}
+
+func singleRune(s string) (rune, bool) {
+	r, n := utf8.DecodeRuneInString(s)
+	return r, n == len(s) && r != utf8.RuneError
+}
+
+// Returns a reader for CSV content of the given type, configured with its
+// dialect and decoding its charset. The content is UTF-8 if the type has no
+// charset. Since encoding/csv only supports double quotes, other quote
+// characters are not supported.
+func NewReader(r io.Reader, t mime.Type) (*stdcsv.Reader, error) {
+	d, err := FromType(t)
+	if err != nil {
+		return nil, err
+	}
+	if d.Quote != '"' {
+		return nil, fmt.Errorf("%w: quotechar %q", ErrUnsupportedDialect, d.Quote)
+	}
+	label := t.Param("charset")
+	if label == "" {
+		label = mime.UTF_8
+	}
+	r, err = charset.NewReader(r, label)
+	if err != nil {
+		return nil, err
+	}
+	if d.LineTerminator == "\r" {
+		r = &crReader{r: r, quote: byte(d.Quote)}
+	}
+	c := stdcsv.NewReader(r)
+	c.Comma = d.Delimiter
+	return c, nil
+}
+
+// Returns a writer of CSV content of the given type, configured with its
+// dialect. The writer produces UTF-8, so the type should not declare another
+// charset.
+func NewWriter(w io.Writer, t mime.Type) (*stdcsv.Writer, error) {
+	d, err := FromType(t)
+	if err != nil {
+		return nil, err
+	}
+	if d.Quote != '"' {
+		return nil, fmt.Errorf("%w: quotechar %q", ErrUnsupportedDialect, d.Quote)
+	}
+	if d.LineTerminator == "\r" {
+		return nil, fmt.Errorf("%w: lineterminator %q", ErrUnsupportedDialect, d.LineTerminator)
+	}
+	if v := t.Param("charset"); v != "" {
+		if name, _ := charset.Lookup(v); name != charset.UTF8 {
+			return nil, fmt.Errorf("%w: %s", charset.ErrUnsupportedCharset, v)
+		}
+	}
+	c := stdcsv.NewWriter(w)
+	c.Comma = d.Delimiter
+	c.UseCRLF = d.LineTerminator == "\r\n"
+	return c, nil
+}
+
+// Translates line terminators which are a single carriage return, which
+// encoding/csv does not recognize, into line feeds. Carriage returns inside
+// quoted fields are content and are left as they are. An escaped quote, which
+// is a pair of quotes, leaves a field quoted.
+type crReader struct {
+	r      io.Reader
+	quote  byte
+	quoted bool
+}
+
+func (c *crReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	for i := 0; i < n; i++ {
+		switch {
+		case b[i] == c.quote:
+			c.quoted = !c.quoted
+		case b[i] == '\r' && !c.quoted:
+			b[i] = '\n'
+		}
+	}
+	return n, err
+}
+
+// The number of records Sniff examines.
+const sniffRecords = 50
+
+// Delimiters Sniff considers, in the order ties are resolved.
+var delimiters = []byte{',', ';', '\t', '|'}
+
+// Fields enclosed in single or double quotes.
+var (
+	doubleQuoted = regexp.MustCompile(`(?m)(?:^|[,;\t|])[ \t]*"[^"\r\n]*"[ \t]*(?:[,;\t|]|\r?$)`)
+	singleQuoted = regexp.MustCompile(`(?m)(?:^|[,;\t|])[ \t]*'[^'\r\n]*'[ \t]*(?:[,;\t|]|\r?$)`)
+)
+
+// Detects the dialect of delimited text from its leading bytes. The quote
+// character is the one which most often encloses whole fields, the
+// delimiter is the one which splits the most records into the same number of
+// fields, and the first record is a header when it differs from the records
+// after it the way names differ from values: it is not numeric where they
+// are, or its length differs where theirs is the same.
+func Sniff(b []byte) Dialect {
+	d := RFC4180
+	b = trimBOM(b)
+	if len(singleQuoted.FindAllIndex(b, -1)) > len(doubleQuoted.FindAllIndex(b, -1)) {
+		d.Quote = '\''
+	}
+
+	var (
+		best     [][]string
+		bestRate float64
+		bestSize int
+	)
+	for _, c := range delimiters {
+		records, terminator := split(b, c, byte(d.Quote), sniffRecords)
+		if terminator != "" {
+			d.LineTerminator = terminator
+		}
+		size, count := modalLength(records)
+		if size < 2 {
+			continue
+		}
+		rate := float64(count) / float64(len(records))
+		if rate > bestRate || rate == bestRate && size > bestSize {
+			d.Delimiter, best, bestRate, bestSize = rune(c), records, rate, size
+		}
+	}
+	if best == nil {
+		best, _ = split(b, ',', byte(d.Quote), sniffRecords)
+	}
+	d.Header = hasHeader(best)
+	return d
+}
+
+// Detects the dialect and charset of delimited text and returns the text/csv
+// type which describes it.
+func Detect(b []byte) mime.Type {
+	return charset.Apply(Sniff(b).Type(), b)
+}
+
+func trimBOM(b []byte) []byte {
+	if len(b) >= 3 && string(b[:3]) == "\xef\xbb\xbf" {
+		return b[3:]
+	}
+	return b
+}
+
+// Splits delimited text into at most limit records and returns the first
+// line terminator found outside of a quoted field. Empty lines are skipped
+// and a record cut off at the end of the text is dropped, unless it is the
+// only one.
+func split(b []byte, delimiter, quote byte, limit int) ([][]string, string) {
+	var (
+		records    [][]string
+		record     []string
+		field      []byte
+		quoted     bool
+		start      = true // at the start of a field
+		terminator string
+	)
+	for i := 0; i < len(b) && len(records) < limit; i++ {
+		c := b[i]
+		switch {
+		case quoted:
+			if c != quote {
+				field = append(field, c)
+			} else if i+1 < len(b) && b[i+1] == quote {
+				field = append(field, c)
+				i++
+			} else {
+				quoted = false
+			}
+		case c == quote && start:
+			quoted, start = true, false
+		case c == delimiter:
+			record = append(record, string(field))
+			field, start = field[:0], true
+		case c == '\r' || c == '\n':
+			t := string(c)
+			if c == '\r' && i+1 < len(b) && b[i+1] == '\n' {
+				t, i = "\r\n", i+1
+			}
+			if terminator == "" {
+				terminator = t
+			}
+			if len(record) > 0 || len(field) > 0 {
+				records = append(records, append(record, string(field)))
+			}
+			record, field, start = nil, field[:0], true
+		default:
+			field, start = append(field, c), false
+		}
+	}
+	if len(records) == 0 && (len(record) > 0 || len(field) > 0) {
+		records = append(records, append(record, string(field)))
+	}
+	return records, terminator
+}
+
+// Returns the most common number of fields in the records and the number of
+// records which have it.
+func modalLength(records [][]string) (int, int) {
+	counts := make(map[int]int)
+	var size, count int
+	for _, e := range records {
+		n := len(e)
+		counts[n]++
+		if counts[n] > count || counts[n] == count && n > size {
+			size, count = n, counts[n]
+		}
+	}
+	return size, count
+}
+
+// Reports whether the first record is a header. Each column in which the
+// records after the first are all numeric, or all the same length, votes
+// for a header when the first record differs and against it otherwise. A
+// single record after the first says nothing about lengths.
+func hasHeader(records [][]string) bool {
+	if len(records) < 2 {
+		return false
+	}
+	header := records[0]
+	var rows [][]string
+	for _, e := range records[1:] {
+		if len(e) == len(header) {
+			rows = append(rows, e)
+		}
+	}
+	if len(rows) == 0 {
+		return false
+	}
+
+	var votes int
+	for i, e := range header {
+		numeric, length := true, len(rows[0][i])
+		for _, r := range rows {
+			if !isNumber(r[i]) {
+				numeric = false
+			}
+			if len(r[i]) != length {
+				length = -1
+			}
+		}
+		switch {
+		case numeric:
+			if isNumber(e) {
+				votes--
+			} else {
+				votes++
+			}
+		case length >= 0 && len(rows) > 1:
+			if len(e) != length {
+				votes++
+			} else {
+				votes--
+			}
+		}
+	}
+	return votes > 0
+}
+
+// Reports whether a field is a number, with a decimal point or comma.
+func isNumber(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	return err == nil
+}
@@ -0,0 +1,213 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/charset"
+)
+
+func TestSniff(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		result Dialect
+	}{
+		{
+			"RFC 4180",
+			"name,age,city\r\nAlice,34,Boston\r\nBob,27,\"New York, NY\"\r\n",
+			Dialect{Delimiter: ',', Quote: '"', Header: true, LineTerminator: "\r\n"},
+		},
+		{
+			"No header",
+			"Alice,34,Boston\nBob,27,Chicago\nCarol,45,Denver\n",
+			Dialect{Delimiter: ',', Quote: '"', Header: false, LineTerminator: "\n"},
+		},
+		{
+			"Semicolons and decimal commas",
+			"article;price;quantity\nwidget;1,50;3\ngadget;12,25;10\ngizmo;0,99;7\n",
+			Dialect{Delimiter: ';', Quote: '"', Header: true, LineTerminator: "\n"},
+		},
+		{
+			"Tabs",
+			"id\tname\tzipcode\n1\tAlice\tAB12\n2\tBob\tCD34\n",
+			Dialect{Delimiter: '\t', Quote: '"', Header: true, LineTerminator: "\n"},
+		},
+		{
+			"Pipes",
+			"1|Alice|Boston\n2|Bob|Chicago\n",
+			Dialect{Delimiter: '|', Quote: '"', Header: false, LineTerminator: "\n"},
+		},
+		{
+			"Single quotes",
+			"'name';'note'\r'Alice';'a; b'\r'Bob';'c'\r",
+			Dialect{Delimiter: ';', Quote: '\'', Header: false, LineTerminator: "\r"},
+		},
+		{
+			"Quoted line breaks",
+			"\xef\xbb\xbfid,comment\n1,\"first\nsecond\"\n2,\"third; fourth\"\n",
+			Dialect{Delimiter: ',', Quote: '"', Header: true, LineTerminator: "\n"},
+		},
+		{
+			"Single column",
+			"name\nAlice\nBob\n",
+			Dialect{Delimiter: ',', Quote: '"', Header: false, LineTerminator: "\n"},
+		},
+		{
+			"Truncated",
+			"a;b;c\n1;2;3\n4;5;6\n7;",
+			Dialect{Delimiter: ';', Quote: '"', Header: true, LineTerminator: "\n"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if result := Sniff([]byte(testCase.input)); result != testCase.result {
+				t.Errorf("Unexpected dialect %+v, expected %+v", result, testCase.result)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	excel := "Prénom;Ville\r\nHélène;Zürich\r\n"
+	testCases := []struct {
+		name   string
+		input  []byte
+		result mime.Type
+	}{
+		{"RFC 4180", []byte("a,b\r\n1,2\r\n"), "text/csv;charset=utf-8;header=present"},
+		{"Excel", []byte(strings.NewReplacer("é", "\xe9", "è", "\xe8", "ü", "\xfc").Replace(excel)), `text/csv;charset=windows-1252;delimiter=";";header=absent`},
+		{"Tabs", []byte("1\t2\n3\t4\n"), "text/csv;charset=utf-8;delimiter=tab;header=absent;lineterminator=lf"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if result := Detect(testCase.input); result != testCase.result {
+				t.Errorf("Unexpected type %q, expected %q", result, testCase.result)
+			}
+		})
+	}
+}
+
+func TestFromType(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  mime.Type
+		result Dialect
+		err    error
+	}{
+		{"Default", mime.CSV, RFC4180, nil},
+		{"Header", "text/csv; header=present", Dialect{',', '"', true, "\r\n"}, nil},
+		{"Delimiter", `text/csv; delimiter=";"; header=absent`, Dialect{';', '"', false, "\r\n"}, nil},
+		{"Tab", "text/csv; delimiter=tab; lineterminator=LF", Dialect{'\t', '"', false, "\n"}, nil},
+		{"Quote", "text/csv; quotechar='", Dialect{',', '\'', false, "\r\n"}, nil},
+		{"TSV", TSV, Dialect{'\t', '"', false, "\r\n"}, nil},
+		{"Invalid header", "text/csv; header=maybe", Dialect{}, ErrInvalidDialect},
+		{"Invalid delimiter", `text/csv; delimiter=";;"`, Dialect{}, ErrInvalidDialect},
+		{"Invalid line terminator", "text/csv; lineterminator=nel", Dialect{}, ErrInvalidDialect},
+		{"Conflict", `text/csv; delimiter="\""`, Dialect{}, ErrInvalidDialect},
+		{"Not CSV", mime.JSON, Dialect{}, ErrNotCSV},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := FromType(testCase.input)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			if result != testCase.result {
+				t.Errorf("Unexpected dialect %+v, expected %+v", result, testCase.result)
+			}
+		})
+	}
+}
+
+func TestTypeRoundTrip(t *testing.T) {
+	for _, d := range []Dialect{
+		RFC4180,
+		{';', '"', true, "\n"},
+		{'\t', '\'', false, "\r"},
+		{' ', '"', true, "\r\n"},
+	} {
+		result, err := FromType(d.Type())
+		if err != nil {
+			t.Errorf("Unexpected error \"%s\" for %q", err, d.Type())
+		} else if result != d {
+			t.Errorf("Unexpected dialect %+v from %q, expected %+v", result, d.Type(), d)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		t       mime.Type
+		records [][]string
+		err     error
+	}{
+		{"RFC 4180", "a,b\r\n1,\"2, 3\"\r\n", mime.CSV, [][]string{{"a", "b"}, {"1", "2, 3"}}, nil},
+		{"Semicolons", "a;b\n1,5;2\n", `text/csv; delimiter=";"`, [][]string{{"a", "b"}, {"1,5", "2"}}, nil},
+		{"BOM", "\xef\xbb\xbfa,b\n", mime.CSV, [][]string{{"a", "b"}}, nil},
+		{"Windows-1252", "H\xe9l\xe8ne;Z\xfcrich\n", `text/csv; charset=windows-1252; delimiter=";"`, [][]string{{"Hélène", "Zürich"}}, nil},
+		{"Carriage returns", "a,b\r1,2\r", "text/csv; lineterminator=cr", [][]string{{"a", "b"}, {"1", "2"}}, nil},
+		{"Carriage returns in quotes", "a,\"b\r\"\"c\"\"\"\r1,2\r", "text/csv; lineterminator=cr", [][]string{{"a", "b\r\"c\""}, {"1", "2"}}, nil},
+		{"TSV", "a\tb\n", TSV, [][]string{{"a", "b"}}, nil},
+		{"Single quotes", "", "text/csv; quotechar='", nil, ErrUnsupportedDialect},
+		{"Unsupported charset", "", "text/csv; charset=shift_jis", nil, charset.ErrUnsupportedCharset},
+		{"Not CSV", "", mime.JSON, nil, ErrNotCSV},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(testCase.input), testCase.t)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			if err != nil {
+				return
+			}
+			records, err := r.ReadAll()
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\"", err)
+			} else if !reflect.DeepEqual(records, testCase.records) {
+				t.Errorf("Unexpected records %q, expected %q", records, testCase.records)
+			}
+		})
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		t      mime.Type
+		output string
+		err    error
+	}{
+		{"RFC 4180", mime.CSV, "a,b\r\n1,\"2,3\"\r\n", nil},
+		{"Semicolons", `text/csv; delimiter=";"; lineterminator=lf`, "a;b\n1;2,3\n", nil},
+		{"Carriage returns", "text/csv; lineterminator=cr", "", ErrUnsupportedDialect},
+		{"Charset", "text/csv; charset=windows-1252", "", charset.ErrUnsupportedCharset},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			b := &strings.Builder{}
+			w, err := NewWriter(b, testCase.t)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			if err != nil {
+				return
+			}
+			w.WriteAll([][]string{{"a", "b"}, {"1", "2,3"}})
+			if b.String() != testCase.output {
+				t.Errorf("Unexpected output %q, expected %q", b.String(), testCase.output)
+			}
+		})
+	}
+}
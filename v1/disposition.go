@@ -0,0 +1,156 @@
+package mime
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+var ErrInvalidDisposition = errors.New("invalid content disposition")
+
+// Disposition types defined by RFC 6266.
+const (
+	DispositionAttachment = "attachment"
+	DispositionInline     = "inline"
+)
+
+// Disposition describes a Content-Disposition header, per RFC 6266.
+type Disposition struct {
+	Type     string            // the disposition type, e.g. "attachment"
+	Filename string            // the filename, which may contain any characters
+	Params   map[string]string // any other parameters
+}
+
+// Attachment creates an attachment disposition for a file of the given type.
+// The filename is given an extension which agrees with the type.
+func Attachment(filename string, t Type) Disposition {
+	return Disposition{Type: DispositionAttachment, Filename: conformExt(filename, t)}
+}
+
+// Inline creates an inline disposition for a file of the given type. The
+// filename is given an extension which agrees with the type.
+func Inline(filename string, t Type) Disposition {
+	return Disposition{Type: DispositionInline, Filename: conformExt(filename, t)}
+}
+
+// String formats the disposition as a Content-Disposition header value. The
+// filename is always given as an ASCII filename parameter, and when that
+// cannot represent it exactly, also as an RFC 5987 filename* parameter
+// encoded as UTF-8, which recipients prefer.
+func (d Disposition) String() string {
+	b := &strings.Builder{}
+	if d.Type != "" {
+		b.WriteString(strings.ToLower(d.Type))
+	} else {
+		b.WriteString(DispositionAttachment)
+	}
+	if d.Filename != "" {
+		filename := strings.ToValidUTF8(d.Filename, "\uFFFD")
+		fallback := asciiFilename(filename)
+		b.WriteString("; filename=")
+		b.WriteString(lex.Quote(fallback))
+		if fallback != filename {
+			b.WriteString("; filename*=UTF-8''")
+			b.WriteString(encodeExtValue(filename))
+		}
+	}
+	keys := make([]string, 0, len(d.Params))
+	for k := range d.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString("; ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(lex.Quote(d.Params[k]))
+	}
+	return b.String()
+}
+
+// ParseContentDisposition parses a Content-Disposition header value. When
+// both filename and filename* parameters are present the filename* value is
+// used, per RFC 6266, section 4.3. The filename is returned as sent, so it
+// should be sanitized before it is used to name a file.
+func ParseContentDisposition(v string) (Disposition, error) {
+	t, p, err := mime.ParseMediaType(v)
+	if err != nil {
+		return Disposition{}, fmt.Errorf("%w: %v", ErrInvalidDisposition, err)
+	}
+	d := Disposition{Type: t, Filename: p["filename"]}
+	for k, e := range p {
+		if k != "filename" {
+			if d.Params == nil {
+				d.Params = make(map[string]string)
+			}
+			d.Params[k] = e
+		}
+	}
+	return d, nil
+}
+
+// conformExt makes the extension of a filename agree with the type. A name
+// which already has one of the type's extensions is left alone; an extension
+// which belongs to some other type is replaced and otherwise the preferred
+// extension of the type is appended. Names are left alone for types without
+// a known extension.
+func conformExt(filename string, t Type) string {
+	exts := t.Exts()
+	if len(exts) == 0 {
+		return filename
+	}
+	ext := path.Ext(filename)
+	for _, e := range exts {
+		if strings.EqualFold(e, ext) {
+			return filename
+		}
+	}
+	if ext != "" && TypeByExtension(ext) != Invalid {
+		filename = filename[:len(filename)-len(ext)]
+	}
+	return filename + exts[0]
+}
+
+// asciiFilename produces a fallback for a filename which recipients that do
+// not support RFC 5987 can use. Characters outside of printable ASCII are
+// replaced, as are '%' and '\', which some recipients interpret, per RFC
+// 6266, appendix D.
+func asciiFilename(s string) string {
+	b := &strings.Builder{}
+	for _, r := range s {
+		if r < 0x20 || r >= 0x7f || r == '%' || r == '\\' || r == '"' {
+			b.WriteByte('_')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// encodeExtValue percent-encodes a UTF-8 string as the value-chars of an
+// ext-value, per RFC 5987, section 3.2.1.
+func encodeExtValue(s string) string {
+	const hex = "0123456789ABCDEF"
+	b := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+		}
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	// RFC 5987, 3.2.1. Parameter Value Character Set and Language Information
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
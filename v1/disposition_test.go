@@ -0,0 +1,114 @@
+package mime
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisposition(t *testing.T) {
+	tests := []struct {
+		In  Disposition
+		Out string
+	}{
+		{
+			In:  Attachment("report", CSV),
+			Out: `attachment; filename=report.csv`,
+		},
+		{
+			In:  Attachment("report.csv", CSV),
+			Out: `attachment; filename=report.csv`,
+		},
+		{
+			In:  Attachment("index.htm", HTML),
+			Out: `attachment; filename=index.htm`,
+		},
+		{
+			In:  Attachment("report.json", CSV),
+			Out: `attachment; filename=report.csv`,
+		},
+		{
+			In:  Attachment("report.2024", CSV),
+			Out: `attachment; filename=report.2024.csv`,
+		},
+		{
+			In:  Inline("photo", Type("image/jpeg")),
+			Out: `inline; filename=photo.jpg`,
+		},
+		{
+			In:  Attachment("annual report.pdf", Type("application/pdf")),
+			Out: `attachment; filename="annual report.pdf"`,
+		},
+		{
+			In:  Attachment("résumé", Type("application/pdf")),
+			Out: `attachment; filename=r_sum_.pdf; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`,
+		},
+		{
+			In:  Attachment(`100% "done"`, Text),
+			Out: `attachment; filename="100_ _done_.txt"; filename*=UTF-8''100%25%20%22done%22.txt`,
+		},
+		{
+			In:  Attachment("data", Type("application/x-go-mime-unknown")),
+			Out: `attachment; filename=data`,
+		},
+		{
+			In:  Disposition{Type: "Inline", Params: map[string]string{"size": "12", "creation-date": "Wed, 12 Feb 1997 16:29:51 -0500"}},
+			Out: `inline; creation-date="Wed, 12 Feb 1997 16:29:51 -0500"; size=12`,
+		},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Out, e.In.String(), fmt.Sprintf("#%d", i))
+	}
+}
+
+func TestParseContentDisposition(t *testing.T) {
+	tests := []struct {
+		In  string
+		Out Disposition
+		Err error
+	}{
+		{
+			In:  `attachment; filename=report.csv`,
+			Out: Disposition{Type: DispositionAttachment, Filename: "report.csv"},
+		},
+		{
+			In:  `INLINE; filename="annual report.pdf"`,
+			Out: Disposition{Type: DispositionInline, Filename: "annual report.pdf"},
+		},
+		{
+			In:  `attachment; filename=r_sum_.pdf; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`,
+			Out: Disposition{Type: DispositionAttachment, Filename: "résumé.pdf"},
+		},
+		{
+			In:  `attachment; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf; filename=r_sum_.pdf`,
+			Out: Disposition{Type: DispositionAttachment, Filename: "résumé.pdf"},
+		},
+		{
+			In:  `attachment; filename=report.csv; size=12`,
+			Out: Disposition{Type: DispositionAttachment, Filename: "report.csv", Params: map[string]string{"size": "12"}},
+		},
+		{
+			In:  `attachment; filename=`,
+			Err: ErrInvalidDisposition,
+		},
+	}
+	for i, e := range tests {
+		d, err := ParseContentDisposition(e.In)
+		if e.Err != nil {
+			assert.True(t, errors.Is(err, e.Err), fmt.Sprintf("#%d", i))
+		} else if assert.NoError(t, err, fmt.Sprintf("#%d", i)) {
+			assert.Equal(t, e.Out, d, fmt.Sprintf("#%d", i))
+		}
+	}
+}
+
+func TestDispositionRoundTrip(t *testing.T) {
+	for i, e := range []string{"report.csv", "annual report.csv", "résumé.csv", `a "quoted"\name.csv`, "日本語.csv"} {
+		d, err := ParseContentDisposition(Attachment(e, CSV).String())
+		if assert.NoError(t, err, fmt.Sprintf("#%d", i)) {
+			assert.Equal(t, e, d.Filename, fmt.Sprintf("#%d", i))
+		}
+	}
+}
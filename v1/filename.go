@@ -0,0 +1,101 @@
+package mime
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxFilenameLength is the longest filename, in bytes, SafeFilename
+// produces; most filesystems do not allow longer names.
+const MaxFilenameLength = 255
+
+// The name SafeFilename uses when nothing is left of the original.
+const defaultFilename = "file"
+
+// Names which refer to devices on Windows, regardless of extension.
+var reservedFilenames = map[string]struct{}{
+	"con": {}, "prn": {}, "aux": {}, "nul": {},
+	"com1": {}, "com2": {}, "com3": {}, "com4": {}, "com5": {}, "com6": {}, "com7": {}, "com8": {}, "com9": {},
+	"lpt1": {}, "lpt2": {}, "lpt3": {}, "lpt4": {}, "lpt5": {}, "lpt6": {}, "lpt7": {}, "lpt8": {}, "lpt9": {},
+}
+
+// SafeFilename produces a name which is safe to store a file of the given
+// type under from an untrusted name, such as one supplied with an upload:
+//
+//   - the name is normalized to Unicode normalization form NFKC, so that
+//     visually identical names are the same and compatibility characters,
+//     like fullwidth solidus, cannot stand in for the ones they resemble;
+//   - any directory components are removed, whether separated by '/' or '\';
+//   - control and bidirectional formatting characters are removed and
+//     characters which are reserved on common filesystems are replaced;
+//   - leading and trailing dots and spaces are removed, and names reserved
+//     for devices on Windows are prefixed;
+//   - an extension which disagrees with the type is replaced if it belongs
+//     to another type and otherwise the type's extension is appended;
+//   - the name is truncated to MaxFilenameLength bytes, preserving the
+//     extension.
+func SafeFilename(name string, t Type) string {
+	name = norm.NFKC.String(name)
+	if x := strings.LastIndexAny(name, `/\`); x >= 0 {
+		name = name[x+1:]
+	}
+	name = sanitizeFilenameChars(name)
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		name = defaultFilename
+	}
+	if stem, _, _ := strings.Cut(name, "."); isReservedFilename(stem) {
+		name = "_" + name
+	}
+
+	name = conformExt(name, t)
+	return truncateFilename(name, MaxFilenameLength)
+}
+
+func isReservedFilename(s string) bool {
+	_, ok := reservedFilenames[strings.ToLower(strings.TrimSpace(s))]
+	return ok
+}
+
+// sanitizeFilenameChars removes characters which are invisible or alter how
+// a name is displayed, and replaces those which are reserved in filenames on
+// common filesystems.
+func sanitizeFilenameChars(s string) string {
+	b := &strings.Builder{}
+	for _, r := range strings.ToValidUTF8(s, "") {
+		switch {
+		case unicode.IsControl(r), unicode.Is(unicode.Bidi_Control, r), r == '\uFEFF':
+			// removed
+		case strings.ContainsRune(`<>:"|?*`, r):
+			b.WriteByte('_')
+		case unicode.IsSpace(r):
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// truncateFilename shortens a name to at most n bytes without splitting a
+// character, preserving its extension. Extensions which are too long to
+// preserve are truncated along with the rest of the name.
+func truncateFilename(name string, n int) string {
+	if len(name) <= n {
+		return name
+	}
+	ext := path.Ext(name)
+	if len(ext) >= n/2 {
+		ext = ""
+	}
+	stem := name[:len(name)-len(ext)]
+	limit := n - len(ext)
+	for limit > 0 && !utf8.RuneStart(stem[limit]) {
+		limit--
+	}
+	return strings.TrimRight(stem[:limit], ". ") + ext
+}
@@ -0,0 +1,68 @@
+package mime
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeFilename(t *testing.T) {
+	tests := []struct {
+		In   string
+		Type Type
+		Out  string
+	}{
+		{"report.csv", CSV, "report.csv"},
+		{"report", CSV, "report.csv"},
+		{"report.exe", CSV, "report.csv"},
+		{"report.2024", CSV, "report.2024.csv"},
+		{"REPORT.CSV", CSV, "REPORT.CSV"},
+		{"index.htm", HTML, "index.htm"},
+		{"../../etc/passwd", Text, "passwd.txt"},
+		{`C:\Users\me\Desktop\photo.jpeg`, Type("image/jpeg"), "photo.jpeg"},
+		{"in\x00voice\r\n.pdf", Type("application/pdf"), "invoice.pdf"},
+		{"what?<is>:this|*.txt", Text, "what__is__this__.txt"},
+		{"harmless\u202Egpj.exe", Type("image/jpeg"), "harmlessgpj.jpg"},
+		{"re\u0301sume\u0301.pdf", Type("application/pdf"), "résumé.pdf"},
+		{"résumé.pdf", Type("application/pdf"), "résumé.pdf"},
+		{"Ha\u0306noi.txt", Text, "Hănoi.txt"},
+		{"\u1100\u1161.txt", Text, "\uac00.txt"},
+		{"\ufb01le.txt", Text, "file.txt"},
+		{"\uff32\uff45\uff50\uff4f\uff52\uff54.txt", Text, "Report.txt"},
+		{"..\uff0f..\uff0fetc\uff0fpasswd", Text, "passwd.txt"},
+		{"...hidden. ", Text, "hidden.txt"},
+		{"", CSV, "file.csv"},
+		{"../", CSV, "file.csv"},
+		{"CON.txt", Text, "_CON.txt"},
+		{"lpt1", Text, "_lpt1.txt"},
+		{"console.txt", Text, "console.txt"},
+		{"data.bin", Type("application/x-go-mime-unknown"), "data.bin"},
+		{"tab\there.txt", Text, "tabhere.txt"},
+		{"no\u00a0break.txt", Text, "no break.txt"},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Out, SafeFilename(e.In, e.Type), fmt.Sprintf("#%d", i))
+	}
+}
+
+func TestSafeFilenameLength(t *testing.T) {
+	tests := []struct {
+		In   string
+		Type Type
+		Ext  string
+	}{
+		{strings.Repeat("a", 300) + ".csv", CSV, ".csv"},
+		{strings.Repeat("a", 300), CSV, ".csv"},
+		{strings.Repeat("é", 200) + ".csv", CSV, ".csv"},
+		{strings.Repeat("日", 100) + ".pdf", Type("application/pdf"), ".pdf"},
+	}
+	for i, e := range tests {
+		out := SafeFilename(e.In, e.Type)
+		assert.LessOrEqual(t, len(out), MaxFilenameLength, fmt.Sprintf("#%d", i))
+		assert.True(t, utf8.ValidString(out), fmt.Sprintf("#%d", i))
+		assert.True(t, strings.HasSuffix(out, e.Ext), fmt.Sprintf("#%d", i))
+	}
+}
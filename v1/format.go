@@ -5,6 +5,8 @@ import (
 	"mime"
 	"sort"
 	"strings"
+
+	"github.com/bww/go-mime/v1/internal/lex"
 )
 
 const (
@@ -24,6 +26,8 @@ const (
 	Invalid  = Type("")
 )
 
+// MarkdownCompatible lists the types Markdown content can be served as. Use
+// Fallbacks to find the types any content can be served as.
 var MarkdownCompatible = Options{
 	Text,
 	Markdown,
@@ -31,13 +35,21 @@ var MarkdownCompatible = Options{
 
 // Parse parses a mimetype string and returns a normalized type and the
 // parameters associated with it. If the type has any parameters, they
-// are sorted and the canonical type string is rewritten.
+// are sorted and the canonical type string is rewritten. Parameter values
+// which are not tokens are quoted in the canonical string, so that it can be
+// parsed again: text/csv; delimiter=";" is rewritten as
+// text/csv;delimiter=";" rather than as text/csv;delimiter=;.
 func Parse(v string) (Type, map[string]string, error) {
 	t, p, err := mime.ParseMediaType(v)
 	if err != nil {
 		return Invalid, nil, err
 	}
+	return format(t, p), p, nil
+}
 
+// format produces the canonical type string for a base type and its
+// parameters, which are sorted by name and quoted when they are not tokens.
+func format(t string, p map[string]string) Type {
 	sb := &strings.Builder{}
 	sb.WriteString(t)
 
@@ -51,11 +63,11 @@ func Parse(v string) (Type, map[string]string, error) {
 			sb.WriteString(";")
 			sb.WriteString(e)
 			sb.WriteString("=")
-			sb.WriteString(p[e])
+			sb.WriteString(lex.Quote(p[e]))
 		}
 	}
 
-	return Type(sb.String()), p, nil
+	return Type(sb.String())
 }
 
 // Base strips any parameters that may be present off the end of the
@@ -80,44 +92,43 @@ func (t Type) Matches(s Type) bool {
 	return strings.EqualFold(t.Base().String(), s.Base().String())
 }
 
+// Param returns the value of the named parameter, or an empty string if the
+// type does not have it or cannot be parsed.
+func (t Type) Param(name string) string {
+	_, p, err := mime.ParseMediaType(string(t))
+	if err != nil {
+		return ""
+	}
+	return p[strings.ToLower(name)]
+}
+
+// WithParam produces the canonical form of the type with the named parameter
+// set to the provided value, replacing any value it already had. If the type
+// cannot be parsed it is returned unchanged.
+func (t Type) WithParam(name, value string) Type {
+	b, p, err := mime.ParseMediaType(string(t))
+	if err != nil {
+		return t
+	}
+	p[strings.ToLower(name)] = value
+	return format(b, p)
+}
+
 func (t Type) String() string {
 	return string(t)
 }
 
-// Ext produces a filename extension (including the '.' separator) for
-// a variety of known types.
+// Ext produces the preferred filename extension (including the '.'
+// separator) for the type, as resolved by DefaultResolver, or an empty
+// string if none is known.
 func (t Type) Ext() string {
-	switch t.Base() {
-	case Invalid:
-		return ""
-	case Text:
-		return ".txt"
-	case Markdown:
-		return ".md"
-	case HTML:
-		return ".html"
-	case JSON:
-		return ".json"
-	case CSV:
-		return ".csv"
-	case XML:
-		return ".xml"
-	case GZIP:
-		return ".gz"
-	default:
-		return t.firstExt()
-	}
+	return DefaultResolver.Ext(t)
 }
 
-func (t Type) firstExt() string {
-	e, err := mime.ExtensionsByType(string(t))
-	if err != nil {
-		return ""
-	}
-	if len(e) < 1 {
-		return ""
-	}
-	return e[0]
+// Exts produces every known filename extension for the type, as resolved
+// by DefaultResolver, with the preferred one first.
+func (t Type) Exts() []string {
+	return DefaultResolver.Exts(t)
 }
 
 func (t Type) MarshalJSON() ([]byte, error) {
@@ -154,6 +165,31 @@ func (o Options) Contains(t Type) bool {
 	return false
 }
 
+// Match reports whether the type matches any of the options, ignoring any
+// parameters and case. Options may be wildcards: */* matches every type,
+// image/* matches every image type and application/*+json matches every
+// application type with the json suffix.
+func (o Options) Match(t Type) bool {
+	top, sub, ok := t.split()
+	if !ok {
+		return false
+	}
+	for _, e := range o {
+		otop, osub, ok := e.split()
+		switch {
+		case !ok:
+			continue
+		case otop != "*" && otop != top:
+			continue
+		case osub == "*" || osub == sub:
+			return true
+		case strings.HasPrefix(osub, "*+") && osub[1:] == "+"+t.Suffix() && !t.IsWildcard():
+			return true
+		}
+	}
+	return false
+}
+
 func (o Options) First(d Type) Type {
 	if len(o) < 1 {
 		return d
@@ -52,6 +52,15 @@ func TestParse(t *testing.T) {
 				"alabama": "state",
 			},
 		},
+		{
+			In:   `text/csv; delimiter=";"; header=present`,
+			Type: Type(`text/csv;delimiter=";";header=present`),
+			Base: Type("text/csv"),
+			Params: map[string]string{
+				"delimiter": ";",
+				"header":    "present",
+			},
+		},
 		{
 			In: "text?plain+json; charset=utf8; alabama=state",
 			Err: func(str string, err error) error {
@@ -111,3 +120,46 @@ func TestCompare(t *testing.T) {
 		assert.Equal(t, e.Match, e.A.Matches(e.B), "#%d", i)
 	}
 }
+
+func TestOptionsMatch(t *testing.T) {
+	tests := []struct {
+		Options Options
+		In      Type
+		Match   bool
+	}{
+		{Options{"image/png"}, Type("image/png"), true},
+		{Options{"image/png"}, Type("IMAGE/PNG; foo=bar"), true},
+		{Options{"image/png"}, Type("image/jpeg"), false},
+		{Options{"image/*"}, Type("image/jpeg"), true},
+		{Options{"image/*"}, Type("video/mp4"), false},
+		{Options{"*/*"}, Type("video/mp4"), true},
+		{Options{"text/plain", "application/*+json"}, Type("application/vnd.acme+json"), true},
+		{Options{"application/*+json"}, Type("application/json"), false},
+		{Options{"application/*+json"}, Type("text/vnd.acme+json"), false},
+		{Options{"*/*"}, Invalid, false},
+		{nil, Type("text/plain"), false},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Match, e.Options.Match(e.In), "#%d", i)
+	}
+}
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		In     Type
+		Name   string
+		Value  string
+		Param  string
+		Result Type
+	}{
+		{Type("text/csv"), "charset", "windows-1252", "", Type("text/csv;charset=windows-1252")},
+		{Type("text/csv; header=present"), "charset", "utf-8", "", Type("text/csv;charset=utf-8;header=present")},
+		{Type("text/csv;charset=utf-8"), "Charset", "windows-1252", "utf-8", Type("text/csv;charset=windows-1252")},
+		{Type("TEXT/CSV; CHARSET=utf-8"), "charset", "utf-16le", "utf-8", Type("text/csv;charset=utf-16le")},
+		{Type("text?csv"), "charset", "utf-8", "", Type("text?csv")},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Param, e.In.Param(e.Name), "#%d", i)
+		assert.Equal(t, e.Result, e.In.WithParam(e.Name, e.Value), "#%d", i)
+	}
+}
@@ -0,0 +1,306 @@
+// Command mkregistry builds the table of media types embedded in the mime
+// package from local copies of the IANA media types registry files.
+//
+// The source directory holds CSV files in the format of the per top-level
+// type files published at https://www.iana.org/assignments/media-types/
+// (application.csv, audio.csv, and so on), which have the columns Name,
+// Template and Reference, and an extensions.csv file with the columns Type,
+// Usage, Preferred and Extensions. The copies in the repository are a curated
+// subset of the registry, trimmed to the types the package needs; the
+// published files can be used in their place unmodified.
+//
+// The registry files do not record file extensions or intended usage, which
+// are only found in the individual templates, so extensions.csv supplies
+// them. Types it does not give a usage for are of unspecified usage, unless
+// the registry marks them as obsolete or deprecated. It may also list types
+// which are not registered with IANA but are common enough to need an
+// extension, like application/x-tar.
+//
+// The Preferred column names the extension used when writing files of the
+// type, which must also appear in Extensions; if it is empty the first
+// extension is preferred.
+//
+// A compatible.csv file with the columns Type and Compatible lists, for a
+// type, the types its content can also be served as, separated by spaces.
+// It only needs to list what cannot be derived from the type itself: content
+// with a structured syntax suffix is compatible with the suffix's type and
+// text is compatible with text/plain. Both types must be in the table.
+//
+// To add types, add them to the CSV files, or replace the registry files with
+// current copies, and run go generate in the package directory.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Top-level types, each of which has a CSV file in the registry.
+var topLevelTypes = []string{
+	"application",
+	"audio",
+	"font",
+	"haptics",
+	"image",
+	"message",
+	"model",
+	"multipart",
+	"text",
+	"video",
+}
+
+const (
+	usageUnspecified = ""
+	usageCommon      = "common"
+	usageLimited     = "limited"
+	usageObsolete    = "obsolete"
+)
+
+const (
+	sourceIANA  = "iana"
+	sourceLocal = "local"
+)
+
+type registration struct {
+	Type       string
+	Usage      string
+	Source     string
+	Template   string
+	Reference  string
+	Preferred  string
+	Extensions []string
+	Compatible []string
+}
+
+func main() {
+	var (
+		fSource = flag.String("src", "internal/mediatypes", "The directory containing the media type CSV files.")
+		fOutput = flag.String("out", "registry.tsv", "The file to write the registry to.")
+	)
+	flag.Parse()
+
+	err := run(*fSource, *fOutput)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mkregistry: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(src, out string) error {
+	regs := make(map[string]*registration)
+	for _, e := range topLevelTypes {
+		err := readRegistry(regs, e, filepath.Join(src, e+".csv"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // not every top-level type has registrations
+		} else if err != nil {
+			return err
+		}
+	}
+	err := readExtensions(regs, filepath.Join(src, "extensions.csv"))
+	if err != nil {
+		return err
+	}
+	err = readCompatible(regs, filepath.Join(src, "compatible.csv"))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	err = write(w, regs)
+	if err != nil {
+		return err
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func readCSV(path string, header []string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = len(header)
+	recs, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(recs) < 1 || strings.Join(recs[0], ",") != strings.Join(header, ",") {
+		return nil, fmt.Errorf("%s: expected header %s", path, strings.Join(header, ","))
+	}
+	return recs[1:], nil
+}
+
+// readRegistry reads the registrations for one top-level type. Obsolete and
+// deprecated types are annotated in the Name column, like "javascript
+// (OBSOLETED in favor of text/javascript)". Some older types have no
+// template, in which case the type is derived from the name.
+func readRegistry(regs map[string]*registration, top, path string) error {
+	recs, err := readCSV(path, []string{"Name", "Template", "Reference"})
+	if err != nil {
+		return err
+	}
+	for _, e := range recs {
+		name, note := e[0], ""
+		if x := strings.Index(name, " "); x >= 0 {
+			name, note = name[:x], strings.ToUpper(name[x:])
+		}
+		t := strings.ToLower(strings.TrimSpace(e[1]))
+		if t == "" {
+			t = top + "/" + strings.ToLower(name)
+		}
+		if _, ok := regs[t]; ok {
+			return fmt.Errorf("%s: duplicate registration for %s", path, t)
+		}
+		usage := usageUnspecified
+		if strings.Contains(note, "OBSOLETE") || strings.Contains(note, "DEPRECATED") {
+			usage = usageObsolete
+		}
+		regs[t] = &registration{
+			Type:      t,
+			Usage:     usage,
+			Source:    sourceIANA,
+			Template:  strings.TrimSpace(e[1]),
+			Reference: strings.TrimSpace(e[2]),
+		}
+	}
+	return nil
+}
+
+// readExtensions merges the intended usage and extensions of types into the
+// registrations, adding unregistered types as necessary.
+func readExtensions(regs map[string]*registration, path string) error {
+	recs, err := readCSV(path, []string{"Type", "Usage", "Preferred", "Extensions"})
+	if err != nil {
+		return err
+	}
+	for _, e := range recs {
+		t := strings.ToLower(strings.TrimSpace(e[0]))
+		if strings.Count(t, "/") != 1 {
+			return fmt.Errorf("%s: invalid type: %q", path, e[0])
+		}
+		reg, ok := regs[t]
+		if !ok {
+			reg = &registration{Type: t, Usage: usageUnspecified, Source: sourceLocal}
+			regs[t] = reg
+		}
+		switch u := strings.ToUpper(strings.TrimSpace(e[1])); u {
+		case "":
+		case "COMMON":
+			reg.Usage = usageCommon
+		case "LIMITED", "LIMITED USE":
+			reg.Usage = usageLimited
+		case "OBSOLETE":
+			reg.Usage = usageObsolete
+		default:
+			return fmt.Errorf("%s: invalid usage for %s: %q", path, t, e[1])
+		}
+		for _, x := range strings.Fields(strings.ToLower(e[3])) {
+			if !strings.HasPrefix(x, ".") || len(x) < 2 {
+				return fmt.Errorf("%s: invalid extension for %s: %q", path, t, x)
+			}
+			reg.Extensions = append(reg.Extensions, x)
+		}
+		reg.Preferred = strings.ToLower(strings.TrimSpace(e[2]))
+		if reg.Preferred == "" && len(reg.Extensions) > 0 {
+			reg.Preferred = reg.Extensions[0]
+		}
+		if reg.Preferred != "" && !contains(reg.Extensions, reg.Preferred) {
+			return fmt.Errorf("%s: preferred extension for %s is not one of its extensions: %q", path, t, e[2])
+		}
+	}
+	return nil
+}
+
+// readCompatible merges the types each type is compatible with into the
+// registrations.
+func readCompatible(regs map[string]*registration, path string) error {
+	recs, err := readCSV(path, []string{"Type", "Compatible"})
+	if err != nil {
+		return err
+	}
+	for _, e := range recs {
+		t := strings.ToLower(strings.TrimSpace(e[0]))
+		reg, ok := regs[t]
+		if !ok {
+			return fmt.Errorf("%s: unknown type: %q", path, e[0])
+		}
+		for _, x := range strings.Fields(strings.ToLower(e[1])) {
+			if _, ok := regs[x]; !ok || x == t {
+				return fmt.Errorf("%s: invalid compatible type for %s: %q", path, t, x)
+			}
+			if !contains(reg.Compatible, x) {
+				reg.Compatible = append(reg.Compatible, x)
+			}
+		}
+	}
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// extensions returns the extensions of the registration with the preferred
+// one first.
+func (r *registration) extensions() []string {
+	if r.Preferred == "" {
+		return r.Extensions
+	}
+	exts := []string{r.Preferred}
+	for _, e := range r.Extensions {
+		if e != r.Preferred {
+			exts = append(exts, e)
+		}
+	}
+	return exts
+}
+
+func write(w io.Writer, regs map[string]*registration) error {
+	keys := make([]string, 0, len(regs))
+	for k := range regs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	_, err := fmt.Fprintln(w, "# Code generated by mkregistry; DO NOT EDIT.")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, "# type\tusage\tsource\ttemplate\treference\tpreferred\textensions\tcompatible")
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		e := regs[k]
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Type, e.Usage, e.Source, e.Template, e.Reference, e.Preferred, strings.Join(e.extensions(), " "), strings.Join(e.Compatible, " "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
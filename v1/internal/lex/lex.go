@@ -0,0 +1,106 @@
+// Package lex holds the character classes of the HTTP grammar shared by the
+// header parsers in this module.
+package lex
+
+// Reports whether the byte is whitespace.
+func IsWhiteSpaceChar(c byte) bool {
+	// RFC 7230, 3.2.3. Whitespace
+	return c == 0x09 || c == 0x20 // HTAB or SP
+}
+
+// Reports whether the byte is a decimal digit.
+func IsDigitChar(c byte) bool {
+	// RFC 5234, Appendix B.1. Core Rules
+	return c >= 0x30 && c <= 0x39
+}
+
+// Reports whether the byte is an ASCII letter.
+func IsAlphaChar(c byte) bool {
+	// RFC 5234, Appendix B.1. Core Rules
+	return (c >= 0x41 && c <= 0x5A) || (c >= 0x61 && c <= 0x7A)
+}
+
+// Reports whether the byte may appear in a token.
+func IsTokenChar(c byte) bool {
+	// RFC 7230, 3.2.6. Field Value Components
+	return c == '!' || c == '#' || c == '$' || c == '%' || c == '&' || c == '\'' || c == '*' ||
+		c == '+' || c == '-' || c == '.' || c == '^' || c == '_' || c == '`' || c == '|' || c == '~' ||
+		IsDigitChar(c) ||
+		IsAlphaChar(c)
+}
+
+// Reports whether the string is a non-empty token.
+func IsToken(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !IsTokenChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the string as a token if it is one and otherwise as a quoted
+// string, with quotes and backslashes escaped.
+func Quote(s string) string {
+	// RFC 7230, 3.2.6. Field Value Components
+	if IsToken(s) {
+		return s
+	}
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b = append(b, '\\')
+		}
+		b = append(b, s[i])
+	}
+	b = append(b, '"')
+	return string(b)
+}
+
+// Reports whether the byte may start a restricted-name.
+func IsRestrictedNameFirstChar(c byte) bool {
+	// RFC 6838, 4.2. Naming Requirements
+	return IsAlphaChar(c) || IsDigitChar(c)
+}
+
+// Reports whether the byte may appear in a restricted-name after its first
+// character.
+func IsRestrictedNameChar(c byte) bool {
+	// RFC 6838, 4.2. Naming Requirements
+	return IsRestrictedNameFirstChar(c) ||
+		c == '!' || c == '#' || c == '$' || c == '&' || c == '-' || c == '^' || c == '_' || c == '.' || c == '+'
+}
+
+// Reports whether the byte is a visible ASCII character.
+func IsVisibleChar(c byte) bool {
+	// RFC 5234, Appendix B.1. Core Rules
+	return c >= 0x21 && c <= 0x7E
+}
+
+// Reports whether the byte is obsolete text, which is any non-ASCII byte.
+func IsObsoleteTextChar(c byte) bool {
+	// RFC 7230, 3.2.6. Field Value Components
+	return c >= 0x80 && c <= 0xFF
+}
+
+// Reports whether the byte may appear unescaped in a quoted string.
+func IsQuotedTextChar(c byte) bool {
+	// RFC 7230, 3.2.6. Field Value Components
+	return c == 0x09 || c == 0x20 || // HTAB or SP
+		c == 0x21 ||
+		(c >= 0x23 && c <= 0x5B) ||
+		(c >= 0x5D && c <= 0x7E) ||
+		IsObsoleteTextChar(c)
+}
+
+// Reports whether the byte may follow a backslash in a quoted string.
+func IsQuotedPairChar(c byte) bool {
+	// RFC 7230, 3.2.6. Field Value Components
+	return c == 0x09 || c == 0x20 || // HTAB or SP
+		IsVisibleChar(c) ||
+		IsObsoleteTextChar(c)
+}
@@ -0,0 +1,193 @@
+// Package markdown handles the variant parameter of text/markdown, which
+// identifies the flavour of Markdown content, per RFC 7763 and RFC 7764.
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/accept"
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+var (
+	ErrNotMarkdown      = errors.New("type is not markdown")
+	ErrInvalidVariant   = errors.New("invalid markdown variant")
+	ErrUnknownVariant   = errors.New("unknown markdown variant")
+	ErrDuplicateVariant = errors.New("markdown variant already registered")
+)
+
+// ParamVariant is the parameter of text/markdown which names the variant.
+const ParamVariant = "variant"
+
+// Variant identifies a flavour of Markdown.
+type Variant string
+
+// Variants registered with IANA, per RFC 7763, section 6.1.4 and RFC 7764,
+// section 6.
+const (
+	Original        = Variant("Original")
+	MultiMarkdown   = Variant("MultiMarkdown")
+	GFM             = Variant("GFM")
+	Pandoc          = Variant("pandoc")
+	Fountain        = Variant("Fountain")
+	CommonMark      = Variant("CommonMark")
+	KramdownRFC2629 = Variant("kramdown-rfc2629")
+	RFC7328         = Variant("rfc7328")
+	Extra           = Variant("Extra")
+)
+
+// No variant; the recipient decides how to interpret the content.
+const Unspecified = Variant("")
+
+func (v Variant) String() string {
+	return string(v)
+}
+
+// Returns the text/markdown type for the variant, with the charset RFC 7763
+// requires. The unspecified variant produces text/markdown without a variant
+// parameter.
+func (v Variant) Type() mime.Type {
+	t := mime.Markdown.WithParam("charset", mime.UTF_8)
+	if v != Unspecified {
+		t = t.WithParam(ParamVariant, v.String())
+	}
+	return t
+}
+
+// Returns the media type for the variant, for negotiation. Like the media
+// types the accept package parses, the variant is in lower case.
+func (v Variant) MediaType() accept.MediaType {
+	m := accept.Markdown.With("charset", mime.UTF_8)
+	if v != Unspecified {
+		m = m.With(ParamVariant, strings.ToLower(v.String()))
+	}
+	return m
+}
+
+// Registered variants, keyed by their lower case names.
+var (
+	variantsLock sync.RWMutex
+	variants     = map[string]Variant{}
+)
+
+func init() {
+	for _, e := range []Variant{Original, MultiMarkdown, GFM, Pandoc, Fountain, CommonMark, KramdownRFC2629, RFC7328, Extra} {
+		variants[strings.ToLower(e.String())] = e
+	}
+}
+
+// Registers a variant which is not registered with IANA, so that FromType
+// and Lookup recognize it. It returns an error if the name is not a valid
+// parameter value or the variant is already registered.
+func Register(v Variant) error {
+	if !isVariant(v.String()) {
+		return fmt.Errorf("%w: %q", ErrInvalidVariant, v)
+	}
+	key := strings.ToLower(v.String())
+	variantsLock.Lock()
+	defer variantsLock.Unlock()
+	if _, ok := variants[key]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateVariant, v)
+	}
+	variants[key] = v
+	return nil
+}
+
+// Returns the registered variant with the name, compared without regard to
+// case, in its registered spelling.
+func Lookup(name string) (Variant, bool) {
+	variantsLock.RLock()
+	defer variantsLock.RUnlock()
+	v, ok := variants[strings.ToLower(name)]
+	return v, ok
+}
+
+// Returns the registered variants, sorted by name.
+func Variants() []Variant {
+	variantsLock.RLock()
+	defer variantsLock.RUnlock()
+	result := make([]Variant, 0, len(variants))
+	for _, e := range variants {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].String()) < strings.ToLower(result[j].String())
+	})
+	return result
+}
+
+func isVariant(s string) bool {
+	return lex.IsToken(s)
+}
+
+// Returns the variant named by the variant parameter of a text/markdown
+// type, in its registered spelling, or Unspecified if it has none. A
+// variant which is valid but not registered is returned along with
+// ErrUnknownVariant, so callers can decide how to treat it.
+func FromType(t mime.Type) (Variant, error) {
+	_, params, err := mime.Parse(t.String())
+	if err != nil {
+		return Unspecified, err
+	}
+	if !t.Matches(mime.Markdown) {
+		return Unspecified, ErrNotMarkdown
+	}
+	name, ok := params[ParamVariant]
+	if !ok {
+		return Unspecified, nil
+	}
+	if !isVariant(name) {
+		return Unspecified, fmt.Errorf("%w: %q", ErrInvalidVariant, name)
+	}
+	if v, ok := Lookup(name); ok {
+		return v, nil
+	}
+	return Variant(name), fmt.Errorf("%w: %q", ErrUnknownVariant, name)
+}
+
+// Chooses which of the available variants to respond with according to the
+// Accept header of the request. The first available variant is preferred
+// when the request accepts any Markdown. When the request does not accept
+// Markdown, the fallbacks of text/markdown, like text/plain, are considered,
+// and the type chosen is returned with the Unspecified variant.
+//
+// Variants in the Accept header are compared without regard to case, whether
+// or not they are quoted.
+func Negotiate(request *http.Request, available ...Variant) (mime.Type, Variant, error) {
+	if len(available) == 0 {
+		return mime.Invalid, Unspecified, accept.ErrNoAvailableTypeGiven
+	}
+
+	fallbacks := mime.Fallbacks(mime.Markdown)
+	mediaTypes := make([]accept.MediaType, 0, len(available)+len(fallbacks))
+	for _, e := range available {
+		mediaTypes = append(mediaTypes, e.MediaType())
+	}
+	for _, e := range fallbacks {
+		m, err := accept.FromType(e)
+		if err != nil {
+			return mime.Invalid, Unspecified, err
+		}
+		mediaTypes = append(mediaTypes, m.With("charset", mime.UTF_8))
+	}
+
+	m, _, err := accept.MatchAcceptableMediaType(request, mediaTypes)
+	if err != nil {
+		return mime.Invalid, Unspecified, err
+	}
+	if !m.Mime().Matches(mime.Markdown) {
+		return m.Mime(), Unspecified, nil
+	}
+	for _, e := range available {
+		if strings.EqualFold(e.String(), m.Parameters[ParamVariant]) {
+			return e.Type(), e, nil
+		}
+	}
+	return m.Mime(), Unspecified, nil
+}
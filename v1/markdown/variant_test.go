@@ -0,0 +1,145 @@
+package markdown
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/accept"
+)
+
+func TestFromType(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  mime.Type
+		result Variant
+		err    error
+	}{
+		{"Unspecified", "text/markdown; charset=UTF-8", Unspecified, nil},
+		{"GFM", "text/markdown; charset=UTF-8; variant=GFM", GFM, nil},
+		{"Case", "text/markdown; variant=commonmark", CommonMark, nil},
+		{"Quoted", `text/markdown; variant="pandoc"`, Pandoc, nil},
+		{"Unknown", "text/markdown; variant=Obsidian", Variant("Obsidian"), ErrUnknownVariant},
+		{"Invalid", `text/markdown; variant="G F M"`, Unspecified, ErrInvalidVariant},
+		{"Not markdown", "text/plain; variant=GFM", Unspecified, ErrNotMarkdown},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := FromType(testCase.input)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			if result != testCase.result {
+				t.Errorf("Unexpected variant %q, expected %q", result, testCase.result)
+			}
+		})
+	}
+}
+
+func TestType(t *testing.T) {
+	testCases := []struct {
+		input  Variant
+		result mime.Type
+	}{
+		{GFM, "text/markdown;charset=utf-8;variant=GFM"},
+		{KramdownRFC2629, "text/markdown;charset=utf-8;variant=kramdown-rfc2629"},
+		{Unspecified, "text/markdown;charset=utf-8"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input.String(), func(t *testing.T) {
+			if result := testCase.input.Type(); result != testCase.result {
+				t.Errorf("Unexpected type %q, expected %q", result, testCase.result)
+			}
+			if v, err := FromType(testCase.input.Type()); err != nil || v != testCase.input {
+				t.Errorf("Unexpected variant %q (%v), expected %q", v, err, testCase.input)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const obsidian = Variant("Obsidian")
+	if _, ok := Lookup("obsidian"); ok {
+		t.Fatalf("Expected the variant not to be registered")
+	}
+	if err := Register(obsidian); err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	}
+	t.Cleanup(func() {
+		variantsLock.Lock()
+		delete(variants, "obsidian")
+		variantsLock.Unlock()
+	})
+	if v, ok := Lookup("OBSIDIAN"); !ok || v != obsidian {
+		t.Errorf("Unexpected variant %q", v)
+	}
+	if v, err := FromType("text/markdown; variant=obsidian"); err != nil || v != obsidian {
+		t.Errorf("Unexpected variant %q (%v)", v, err)
+	}
+	if err := Register("obsidian"); !errors.Is(err, ErrDuplicateVariant) {
+		t.Errorf("Unexpected error \"%v\"", err)
+	}
+	if err := Register("gfm"); !errors.Is(err, ErrDuplicateVariant) {
+		t.Errorf("Unexpected error \"%v\"", err)
+	}
+	if err := Register("two words"); !errors.Is(err, ErrInvalidVariant) {
+		t.Errorf("Unexpected error \"%v\"", err)
+	}
+
+	var found bool
+	for _, e := range Variants() {
+		if e == obsidian {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected %q in %v", obsidian, Variants())
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		accept    string
+		available []Variant
+		result    mime.Type
+		variant   Variant
+		err       error
+	}{
+		{"No header", "", []Variant{GFM, CommonMark}, "text/markdown;charset=utf-8;variant=GFM", GFM, nil},
+		{"Any", "*/*", []Variant{CommonMark, GFM}, "text/markdown;charset=utf-8;variant=CommonMark", CommonMark, nil},
+		{"Any markdown", "text/markdown", []Variant{GFM, CommonMark}, "text/markdown;charset=utf-8;variant=GFM", GFM, nil},
+		{"Variant", "text/markdown;variant=CommonMark", []Variant{GFM, CommonMark}, "text/markdown;charset=utf-8;variant=CommonMark", CommonMark, nil},
+		{"Variant case", "text/markdown;variant=gfm", []Variant{CommonMark, GFM}, "text/markdown;charset=utf-8;variant=GFM", GFM, nil},
+		{"Quoted variant case", "text/markdown;variant=\"gfm\"", []Variant{CommonMark, GFM}, "text/markdown;charset=utf-8;variant=GFM", GFM, nil},
+		{"Weighted variants", "text/markdown;variant=GFM;q=0.5, text/markdown;variant=pandoc", []Variant{GFM, Pandoc}, "text/markdown;charset=utf-8;variant=pandoc", Pandoc, nil},
+		{"Unavailable variant", "text/markdown;variant=pandoc, text/plain;q=0.1", []Variant{GFM}, "text/plain;charset=utf-8", Unspecified, nil},
+		{"Text", "text/plain", []Variant{GFM}, "text/plain;charset=utf-8", Unspecified, nil},
+		{"Text preferred", "text/markdown;q=0.5, text/plain", []Variant{GFM}, "text/plain;charset=utf-8", Unspecified, nil},
+		{"Unspecified", "text/markdown", []Variant{Unspecified}, "text/markdown;charset=utf-8", Unspecified, nil},
+		{"Not acceptable", "application/json", []Variant{GFM}, mime.Invalid, Unspecified, accept.ErrNoAcceptableTypeFound},
+		{"Nothing available", "text/markdown", nil, mime.Invalid, Unspecified, accept.ErrNoAvailableTypeGiven},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "http://test.test/", nil)
+			if testCase.accept != "" {
+				request.Header.Set("Accept", testCase.accept)
+			}
+			result, variant, err := Negotiate(request, testCase.available...)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, testCase.err)
+			}
+			if result != testCase.result {
+				t.Errorf("Unexpected type %q, expected %q", result, testCase.result)
+			}
+			if variant != testCase.variant {
+				t.Errorf("Unexpected variant %q, expected %q", variant, testCase.variant)
+			}
+		})
+	}
+}
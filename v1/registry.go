@@ -0,0 +1,189 @@
+package mime
+
+import (
+	_ "embed"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:generate go run ./internal/cmd/mkregistry -src internal/mediatypes -out registry.tsv
+
+// The embedded table of media types, generated by mkregistry. It is a curated
+// subset of the IANA media types registry: the types this package and its
+// users commonly need, with their extensions, plus a few common unregistered
+// types. It is not a complete copy of the registry, so types it does not list
+// may nonetheless be registered.
+//
+//go:embed registry.tsv
+var registryData string
+
+// Usage is the intended usage of a type, as declared in its registration
+// template. The IANA registry files do not record it, so it is only known
+// for types whose usage is curated alongside their extensions, and for types
+// the registry marks as obsolete or deprecated.
+type Usage string
+
+const (
+	UsageUnspecified = Usage("")
+	UsageCommon      = Usage("common")
+	UsageLimited     = Usage("limited")
+	UsageObsolete    = Usage("obsolete")
+)
+
+func (u Usage) String() string {
+	return string(u)
+}
+
+func (u Usage) rank() int {
+	switch u {
+	case UsageCommon:
+		return 0
+	case UsageUnspecified:
+		return 1
+	case UsageLimited:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Registration describes a type in the embedded table.
+type Registration struct {
+	Type       Type     // the type, e.g. "application/json"
+	Usage      Usage    // the intended usage, if it is known
+	Registered bool     // whether the type is registered with IANA
+	Template   string   // the IANA template reference, e.g. "application/json"
+	Reference  string   // the defining documents, e.g. "[RFC8259]"
+	Preferred  string   // the preferred file extension, e.g. ".html", or empty
+	Extensions []string // the file extensions, preferred first, including the '.' separator
+	Compatible Options  // types the content can also be served as, beyond those Fallbacks derives
+}
+
+type registry struct {
+	regs  []Registration
+	types map[string]int
+	exts  map[string][]int
+}
+
+var loadRegistry = sync.OnceValue(func() *registry {
+	return parseRegistry(registryData)
+})
+
+func parseRegistry(data string) *registry {
+	r := &registry{
+		types: make(map[string]int),
+		exts:  make(map[string][]int),
+	}
+	for _, line := range strings.Split(data, "\n") {
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		f := strings.Split(line, "\t")
+		if len(f) != 8 {
+			panic("mime: invalid registry entry: " + line)
+		}
+		reg := Registration{
+			Type:       Type(f[0]),
+			Usage:      Usage(f[1]),
+			Registered: f[2] == "iana",
+			Template:   f[3],
+			Reference:  f[4],
+			Preferred:  f[5],
+			Extensions: strings.Fields(f[6]),
+		}
+		for _, e := range strings.Fields(f[7]) {
+			reg.Compatible = append(reg.Compatible, Type(e))
+		}
+		n := len(r.regs)
+		r.regs = append(r.regs, reg)
+		r.types[f[0]] = n
+		for _, e := range reg.Extensions {
+			r.exts[e] = append(r.exts[e], n)
+		}
+	}
+	for k, e := range r.exts {
+		r.sortByPreference(k, e)
+	}
+	return r
+}
+
+// sortByPreference orders the registrations sharing an extension: types in
+// common use before those of unspecified usage and those before limited and
+// obsolete ones, registered types before
+// unregistered ones, types which list the extension earlier before those
+// which list it later, and otherwise by name.
+func (r *registry) sortByPreference(ext string, ids []int) {
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, b := r.regs[ids[i]], r.regs[ids[j]]
+		if a.Usage.rank() != b.Usage.rank() {
+			return a.Usage.rank() < b.Usage.rank()
+		}
+		if a.Registered != b.Registered {
+			return a.Registered
+		}
+		return indexOf(a.Extensions, ext) < indexOf(b.Extensions, ext)
+	})
+}
+
+func indexOf(s []string, v string) int {
+	for i, e := range s {
+		if e == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *registry) lookup(t Type) (Registration, bool) {
+	n, ok := r.types[strings.ToLower(t.Base().String())]
+	if !ok {
+		return Registration{}, false
+	}
+	return r.regs[n].copy(), true
+}
+
+func (r Registration) copy() Registration {
+	r.Extensions = append([]string(nil), r.Extensions...)
+	r.Compatible = append(Options(nil), r.Compatible...)
+	return r
+}
+
+// Lookup returns the registration for the base of the type, ignoring any
+// parameters and case. It reports false for types the embedded table does
+// not list, which is a curated subset of the IANA registry, so a type it does
+// not know may still be registered.
+func Lookup(t Type) (Registration, bool) {
+	return loadRegistry().lookup(t)
+}
+
+// Registrations returns every registration in the embedded table, ordered by
+// type.
+func Registrations() []Registration {
+	r := loadRegistry()
+	regs := make([]Registration, len(r.regs))
+	for i, e := range r.regs {
+		regs[i] = e.copy()
+	}
+	return regs
+}
+
+func (r *registry) typesByExtension(ext string) Options {
+	ids := r.exts[normalizeExt(ext)]
+	if len(ids) == 0 {
+		return nil
+	}
+	types := make(Options, len(ids))
+	for i, e := range ids {
+		types[i] = r.regs[e].Type
+	}
+	return types
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && ext[0] != '.' {
+		ext = "." + ext
+	}
+	return ext
+}
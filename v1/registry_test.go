@@ -0,0 +1,184 @@
+package mime
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		In  Type
+		Reg Registration
+		OK  bool
+	}{
+		{
+			In: Type("application/json"),
+			Reg: Registration{
+				Type:       "application/json",
+				Usage:      UsageCommon,
+				Registered: true,
+				Template:   "application/json",
+				Reference:  "[RFC8259]",
+				Preferred:  ".json",
+				Extensions: []string{".json"},
+			},
+			OK: true,
+		},
+		{
+			In: Type("Text/HTML; charset=utf-8"),
+			Reg: Registration{
+				Type:       "text/html",
+				Usage:      UsageCommon,
+				Registered: true,
+				Template:   "text/html",
+				Reference:  "[W3C][Robin_Berjon]",
+				Preferred:  ".html",
+				Extensions: []string{".html", ".htm"},
+			},
+			OK: true,
+		},
+		{
+			In: Type("application/javascript"),
+			Reg: Registration{
+				Type:       "application/javascript",
+				Usage:      UsageObsolete,
+				Registered: true,
+				Template:   "application/javascript",
+				Reference:  "[RFC4329][RFC9239]",
+				Preferred:  ".js",
+				Extensions: []string{".js"},
+				Compatible: Options{"text/javascript"},
+			},
+			OK: true,
+		},
+		{
+			In: Type("multipart/mixed"),
+			Reg: Registration{
+				Type:       "multipart/mixed",
+				Usage:      UsageUnspecified,
+				Registered: true,
+				Reference:  "[RFC2046][RFC2045]",
+			},
+			OK: true,
+		},
+		{
+			In: Type("application/x-tar"),
+			Reg: Registration{
+				Type:       "application/x-tar",
+				Usage:      UsageCommon,
+				Preferred:  ".tar",
+				Extensions: []string{".tar"},
+			},
+			OK: true,
+		},
+		{
+			In: Type("application/vnd.acme+json"),
+			OK: false,
+		},
+	}
+	for i, e := range tests {
+		reg, ok := Lookup(e.In)
+		assert.Equal(t, e.OK, ok, fmt.Sprintf("#%d", i))
+		if ok {
+			if len(e.Reg.Extensions) == 0 {
+				e.Reg.Extensions = nil
+			}
+			if len(reg.Extensions) == 0 {
+				reg.Extensions = nil
+			}
+			assert.Equal(t, e.Reg, reg, fmt.Sprintf("#%d", i))
+		}
+	}
+}
+
+func TestLookupCopies(t *testing.T) {
+	reg, ok := Lookup(JSON)
+	assert.True(t, ok)
+	reg.Extensions[0] = ".changed"
+	reg, _ = Lookup(JSON)
+	assert.Equal(t, ".json", reg.Extensions[0])
+}
+
+func TestRegistrations(t *testing.T) {
+	regs := Registrations()
+	assert.NotEmpty(t, regs)
+	assert.True(t, sort.SliceIsSorted(regs, func(i, j int) bool {
+		return regs[i].Type < regs[j].Type
+	}))
+}
+
+func TestTypesByExtension(t *testing.T) {
+	tests := []struct {
+		In    string
+		Types Options
+	}{
+		{".json", Options{JSON}},
+		{"JSON", Options{JSON}},
+		{".js", Options{"text/javascript", "application/javascript"}},
+		{".xml", Options{"application/xml", XML}},
+		{".jpe", Options{"image/jpeg"}},
+		{".tar", Options{"application/x-tar"}},
+		{".unknown", nil},
+		{"", nil},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Types, TypesByExtension(e.In), fmt.Sprintf("#%d", i))
+		assert.Equal(t, e.Types.First(Invalid), TypeByExtension(e.In), fmt.Sprintf("#%d", i))
+	}
+}
+
+func TestExt(t *testing.T) {
+	tests := []struct {
+		In  Type
+		Ext string
+	}{
+		{Text, ".txt"},
+		{HTML, ".html"},
+		{XML, ".xml"},
+		{Type("image/jpeg"), ".jpg"},
+		{Type("text/javascript;charset=utf-8"), ".js"},
+		{Type("application/vnd.openxmlformats-officedocument.wordprocessingml.document"), ".docx"},
+		{Invalid, ""},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Ext, e.In.Ext(), fmt.Sprintf("#%d", i))
+	}
+}
+
+func TestExts(t *testing.T) {
+	tests := []struct {
+		In   Type
+		Exts []string
+	}{
+		{HTML, []string{".html", ".htm"}},
+		{Type("image/jpeg"), []string{".jpg", ".jpe", ".jpeg"}},
+		{Type("text/plain;charset=utf-8"), []string{".txt", ".conf", ".log", ".text"}},
+		{Markdown, []string{".md", ".markdown"}},
+		{GZIP, []string{".gz"}},
+		{Type("multipart/mixed"), nil},
+		{Type("application/x-go-mime-unknown"), nil},
+		{Invalid, nil},
+	}
+	for i, e := range tests {
+		exts := e.In.Exts()
+		assert.Equal(t, e.Exts, exts, fmt.Sprintf("#%d", i))
+		if len(exts) > 0 {
+			assert.Equal(t, exts[0], e.In.Ext(), fmt.Sprintf("#%d", i))
+		} else {
+			assert.Equal(t, "", e.In.Ext(), fmt.Sprintf("#%d", i))
+		}
+	}
+}
+
+func TestPreferredExtension(t *testing.T) {
+	for _, e := range Registrations() {
+		if len(e.Extensions) == 0 {
+			assert.Equal(t, "", e.Preferred, e.Type.String())
+		} else {
+			assert.Equal(t, e.Extensions[0], e.Preferred, e.Type.String())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package mime
+
+import (
+	"mime"
+)
+
+// A Resolver maps types to file extensions and back.
+//
+// A hermetic resolver consults only the embedded registry, so it produces the
+// same results on every host. A system resolver falls back to the host's
+// tables, such as /etc/mime.types, via the standard library for types and
+// extensions the registry does not know, which makes its results depend on
+// the machine it runs on.
+type Resolver struct {
+	system bool
+}
+
+// DefaultResolver is used by Type.Ext and the package-level extension
+// lookups. It is hermetic; programs that want to consult the host's tables
+// can replace it with NewSystemResolver during initialization.
+var DefaultResolver = NewResolver()
+
+// NewResolver creates a hermetic resolver which uses only the embedded
+// registry.
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// NewSystemResolver creates a resolver which uses the embedded registry and
+// falls back to the host's tables.
+func NewSystemResolver() *Resolver {
+	return &Resolver{system: true}
+}
+
+// Hermetic reports whether the resolver uses only the embedded registry.
+func (r *Resolver) Hermetic() bool {
+	return !r.system
+}
+
+// Ext returns the preferred extension, including the '.' separator, for the
+// base of the type, or an empty string if none is known.
+func (r *Resolver) Ext(t Type) string {
+	if reg, ok := loadRegistry().lookup(t); ok {
+		return reg.Preferred
+	}
+	if e := r.Exts(t); len(e) > 0 {
+		return e[0]
+	}
+	return ""
+}
+
+// Exts returns every known extension, including the '.' separator, for the
+// base of the type with the preferred one first. The host's tables do not
+// record a preference, so for types found only there the extensions are in
+// the order the standard library returns them.
+func (r *Resolver) Exts(t Type) []string {
+	if reg, ok := loadRegistry().lookup(t); ok {
+		if len(reg.Extensions) == 0 {
+			return nil
+		}
+		return reg.Extensions
+	}
+	if !r.system || t.Base() == Invalid {
+		return nil
+	}
+	e, err := mime.ExtensionsByType(t.Base().String())
+	if err != nil || len(e) < 1 {
+		return nil
+	}
+	return e
+}
+
+// TypesByExtension returns the types associated with a file extension, which
+// may be given with or without the '.' separator, most preferred first.
+func (r *Resolver) TypesByExtension(ext string) Options {
+	if types := loadRegistry().typesByExtension(ext); len(types) > 0 {
+		return types
+	}
+	if !r.system {
+		return nil
+	}
+	ext = normalizeExt(ext)
+	if ext == "" {
+		return nil
+	}
+	if t := Type(mime.TypeByExtension(ext)); t != Invalid {
+		return Options{t.Base()}
+	}
+	return nil
+}
+
+// TypeByExtension returns the preferred type for a file extension, or
+// Invalid if the extension is not known.
+func (r *Resolver) TypeByExtension(ext string) Type {
+	return r.TypesByExtension(ext).First(Invalid)
+}
+
+// TypesByExtension returns the types associated with a file extension using
+// DefaultResolver.
+func TypesByExtension(ext string) Options {
+	return DefaultResolver.TypesByExtension(ext)
+}
+
+// TypeByExtension returns the preferred type for a file extension using
+// DefaultResolver.
+func TypeByExtension(ext string) Type {
+	return DefaultResolver.TypeByExtension(ext)
+}
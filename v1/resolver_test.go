@@ -0,0 +1,63 @@
+package mime
+
+import (
+	"fmt"
+	"mime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver(t *testing.T) {
+	// Register an extension and type only the host knows about, as if they
+	// were read from /etc/mime.types.
+	err := mime.AddExtensionType(".gomimetest", "application/x-go-mime-test")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		Resolver *Resolver
+		In       Type
+		Ext      string
+		FromExt  string
+		Types    Options
+	}{
+		{
+			Resolver: NewResolver(),
+			In:       Type("image/jpeg"),
+			Ext:      ".jpg",
+			FromExt:  ".jpg",
+			Types:    Options{"image/jpeg"},
+		},
+		{
+			Resolver: NewResolver(),
+			In:       Type("application/x-go-mime-test"),
+			FromExt:  ".gomimetest",
+		},
+		{
+			Resolver: NewSystemResolver(),
+			In:       Type("image/jpeg"),
+			Ext:      ".jpg",
+			FromExt:  ".jpg",
+			Types:    Options{"image/jpeg"},
+		},
+		{
+			Resolver: NewSystemResolver(),
+			In:       Type("application/x-go-mime-test"),
+			Ext:      ".gomimetest",
+			FromExt:  ".gomimetest",
+			Types:    Options{"application/x-go-mime-test"},
+		},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.Ext, e.Resolver.Ext(e.In), fmt.Sprintf("#%d", i))
+		assert.Equal(t, e.Types, e.Resolver.TypesByExtension(e.FromExt), fmt.Sprintf("#%d", i))
+		assert.Equal(t, e.Types.First(Invalid), e.Resolver.TypeByExtension(e.FromExt), fmt.Sprintf("#%d", i))
+	}
+}
+
+func TestDefaultResolverIsHermetic(t *testing.T) {
+	assert.True(t, DefaultResolver.Hermetic())
+	assert.Equal(t, "", Type("application/x-go-mime-unknown").Ext())
+}
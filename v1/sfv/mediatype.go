@@ -0,0 +1,109 @@
+package sfv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bww/go-mime/v1/accept"
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+// Item does not hold a media type.
+var ErrNotMediaType = errors.New("item is not a media type")
+
+// Converts a media type into an Item. The type and subtype become a token,
+// like text/html, and the media type parameters become item parameters,
+// sorted by key. Parameter values are tokens where possible and strings
+// otherwise.
+func FromMediaType(mediaType accept.MediaType) (Item, error) {
+	base := mediaType.Base()
+	item := Item{}
+	if isToken(base) {
+		item.Value = Token(base)
+	} else {
+		item.Value = base
+	}
+
+	keys := make([]string, 0, len(mediaType.Parameters))
+	for k := range mediaType.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !isKey(key) {
+			return Item{}, valueError("media type parameter %q is not a valid key", key)
+		}
+		if value := mediaType.Parameters[key]; isToken(value) {
+			item.Params.Set(key, Token(value))
+		} else {
+			item.Params.Set(key, value)
+		}
+	}
+
+	return item, nil
+}
+
+// Converts an Item holding a media type, as a token or a string, into a
+// MediaType. Item parameters become media type parameters; integers and
+// decimals are converted to their textual form.
+func ToMediaType(item Item) (accept.MediaType, error) {
+	var stringBuilder strings.Builder
+	switch v := item.Value.(type) {
+	case Token:
+		stringBuilder.WriteString(string(v))
+	case string:
+		stringBuilder.WriteString(v)
+	default:
+		return accept.MediaType{}, ErrNotMediaType
+	}
+
+	for _, param := range item.Params {
+		var value string
+		switch v := param.Value.(type) {
+		case Token:
+			value = string(v)
+		case string:
+			value = v
+		case int64, float64:
+			var b strings.Builder
+			if err := serializeBareItem(&b, v); err != nil {
+				return accept.MediaType{}, err
+			}
+			value = b.String()
+		default:
+			return accept.MediaType{}, ErrNotMediaType
+		}
+		for i := 0; i < len(value); i++ {
+			if !lex.IsQuotedPairChar(value[i]) {
+				return accept.MediaType{}, fmt.Errorf("%w: parameter %s cannot be written as a quoted string", ErrNotMediaType, param.Key)
+			}
+		}
+		stringBuilder.WriteByte(';')
+		stringBuilder.WriteString(param.Key)
+		stringBuilder.WriteByte('=')
+		stringBuilder.WriteString(lex.Quote(value))
+	}
+
+	return accept.ParseMediaTypeString(stringBuilder.String())
+}
+
+// Converts every member of a list into a media type. Inner lists are not
+// media types and produce an error.
+func ToMediaTypes(list List) ([]accept.MediaType, error) {
+	mediaTypes := make([]accept.MediaType, 0, len(list))
+	for _, member := range list {
+		item, ok := member.(Item)
+		if !ok {
+			return nil, ErrNotMediaType
+		}
+		mediaType, err := ToMediaType(item)
+		if err != nil {
+			return nil, err
+		}
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	return mediaTypes, nil
+}
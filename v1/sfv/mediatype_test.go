@@ -0,0 +1,87 @@
+package sfv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bww/go-mime/v1/accept"
+)
+
+func TestMediaTypeConversion(t *testing.T) {
+	testCases := []struct {
+		name       string
+		mediaType  accept.MediaType
+		serialized string
+	}{
+		{"Type and subtype", accept.Of("text", "html"), "text/html"},
+		{"Token parameter", accept.Text.With("charset", "utf-8"), "text/plain;charset=utf-8"},
+		{"URI parameter", accept.JSON.With("profile", "https://example.com/v2"), "application/json;profile=https://example.com/v2"},
+		{"String parameter", accept.Text.With("title", "a b"), `text/plain;title="a b"`},
+		{"Sorted parameters", accept.Of("a", "b").With("z", "1").With("y", "x"), `a/b;y=x;z="1"`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			item, err := FromMediaType(testCase.mediaType)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\"", err)
+			}
+			serialized, err := SerializeItem(item)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\"", err)
+			} else if serialized != testCase.serialized {
+				t.Errorf("Invalid serialization, got %s, expected %s", serialized, testCase.serialized)
+			}
+
+			parsed, err := ParseItem(serialized)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\"", err)
+			}
+			result, err := ToMediaType(parsed)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\"", err)
+			} else if !reflect.DeepEqual(result, testCase.mediaType) {
+				t.Errorf("Invalid media type, got %v, expected %v", result, testCase.mediaType)
+			}
+		})
+	}
+}
+
+func TestToMediaTypes(t *testing.T) {
+	list, err := ParseList(`text/html, "application/json";v=2, image/*`)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	}
+	result, err := ToMediaTypes(list)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	}
+	expected := []accept.MediaType{accept.HTML, accept.JSON.With("v", "2"), accept.Wildcard("image")}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid media types, got %v, expected %v", result, expected)
+	}
+
+	for _, value := range []string{"1", "?1", "(text/html)", "text/html;a=?1"} {
+		list, err := ParseList(value)
+		if err != nil {
+			t.Fatalf("Unexpected error \"%s\" for %s", err, value)
+		}
+		if _, err := ToMediaTypes(list); err == nil {
+			t.Errorf("Expected an error for %s", value)
+		}
+	}
+}
+
+func TestToMediaTypeQuoting(t *testing.T) {
+	item := Item{Value: Token("text/plain"), Params: Params{{"title", "caf\u00e9 \"x\" \\"}}}
+	if result, err := ToMediaType(item); err != nil {
+		t.Errorf("Unexpected error \"%s\"", err)
+	} else if expect := accept.Text.With("title", "caf\u00e9 \"x\" \\"); !reflect.DeepEqual(result, expect) {
+		t.Errorf("Invalid media type, got %v, expected %v", result, expect)
+	}
+	item = Item{Value: Token("text/plain"), Params: Params{{"title", "a\x01b"}}}
+	if _, err := ToMediaType(item); !errors.Is(err, ErrNotMediaType) {
+		t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, ErrNotMediaType)
+	}
+}
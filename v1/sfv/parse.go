@@ -0,0 +1,403 @@
+package sfv
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+type parser struct {
+	input  string
+	offset int
+}
+
+// Parses the value of a field whose type is an Item.
+func ParseItem(s string) (Item, error) {
+	p, err := newParser(s)
+	if err != nil {
+		return Item{}, err
+	}
+	item, err := p.parseItem()
+	if err != nil {
+		return Item{}, err
+	}
+	return item, p.end()
+}
+
+// Parses the value of a field whose type is a List.
+func ParseList(s string) (List, error) {
+	// RFC 8941, 4.2.1. Parsing a List
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+
+	list := List{}
+	for !p.empty() {
+		member, err := p.parseItemOrInnerList()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, member)
+
+		if more, err := p.nextMember(); err != nil {
+			return nil, err
+		} else if !more {
+			break
+		}
+	}
+
+	return list, p.end()
+}
+
+// Parses the value of a field whose type is a Dictionary.
+func ParseDictionary(s string) (Dictionary, error) {
+	// RFC 8941, 4.2.2. Parsing a Dictionary
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+
+	dict := Dictionary{}
+	for !p.empty() {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		var member Member
+		if p.peek() == '=' {
+			p.offset++ // skip the equal sign
+			member, err = p.parseItemOrInnerList()
+		} else {
+			var params Params
+			params, err = p.parseParameters()
+			member = Item{Value: true, Params: params}
+		}
+		if err != nil {
+			return nil, err
+		}
+		dict.Set(key, member)
+
+		if more, err := p.nextMember(); err != nil {
+			return nil, err
+		} else if !more {
+			break
+		}
+	}
+
+	return dict, p.end()
+}
+
+func newParser(s string) (*parser, error) {
+	// RFC 8941, 4.2. Parsing Structured Fields
+	for i := 0; i < len(s); i++ {
+		if s[i] > 0x7F {
+			return nil, parseError(i, "non-ASCII character")
+		}
+	}
+	// leading and trailing spaces are discarded, but offsets still refer to
+	// the original value
+	trimmed := strings.TrimLeft(s, " ")
+	return &parser{input: strings.TrimRight(s, " "), offset: len(s) - len(trimmed)}, nil
+}
+
+func (p *parser) empty() bool {
+	return p.offset >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	if p.empty() {
+		return 0
+	}
+	return p.input[p.offset]
+}
+
+func (p *parser) end() error {
+	if !p.empty() {
+		return parseError(p.offset, "unexpected %q", p.peek())
+	}
+	return nil
+}
+
+func (p *parser) skipSpaces() {
+	for !p.empty() && p.peek() == ' ' {
+		p.offset++
+	}
+}
+
+func (p *parser) skipWhiteSpaces() {
+	for !p.empty() && lex.IsWhiteSpaceChar(p.peek()) {
+		p.offset++
+	}
+}
+
+// Consumes the separator between list or dictionary members, reporting
+// whether another member follows.
+func (p *parser) nextMember() (bool, error) {
+	p.skipWhiteSpaces()
+	if p.empty() {
+		return false, nil
+	}
+	if p.peek() != ',' {
+		return false, parseError(p.offset, "expected a comma, found %q", p.peek())
+	}
+	p.offset++ // skip the comma
+	p.skipWhiteSpaces()
+	if p.empty() {
+		return false, parseError(p.offset, "trailing comma")
+	}
+	return true, nil
+}
+
+func (p *parser) parseItemOrInnerList() (Member, error) {
+	// RFC 8941, 4.2.1.1. Parsing an Item or Inner List
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+	return p.parseItem()
+}
+
+func (p *parser) parseInnerList() (InnerList, error) {
+	// RFC 8941, 4.2.1.2. Parsing an Inner List
+	p.offset++ // skip the opening parenthesis
+
+	list := InnerList{Items: []Item{}}
+	for !p.empty() {
+		p.skipSpaces()
+		if p.peek() == ')' {
+			p.offset++ // skip the closing parenthesis
+			params, err := p.parseParameters()
+			if err != nil {
+				return InnerList{}, err
+			}
+			list.Params = params
+			return list, nil
+		}
+
+		item, err := p.parseItem()
+		if err != nil {
+			return InnerList{}, err
+		}
+		list.Items = append(list.Items, item)
+
+		if c := p.peek(); c != ' ' && c != ')' {
+			return InnerList{}, parseError(p.offset, "expected a space or closing parenthesis, found %q", c)
+		}
+	}
+
+	return InnerList{}, parseError(p.offset, "unterminated inner list")
+}
+
+func (p *parser) parseItem() (Item, error) {
+	// RFC 8941, 4.2.3. Parsing an Item
+	value, err := p.parseBareItem()
+	if err != nil {
+		return Item{}, err
+	}
+	params, err := p.parseParameters()
+	if err != nil {
+		return Item{}, err
+	}
+	return Item{Value: value, Params: params}, nil
+}
+
+func (p *parser) parseBareItem() (any, error) {
+	// RFC 8941, 4.2.3.1. Parsing a Bare Item
+	switch c := p.peek(); {
+	case c == '-' || lex.IsDigitChar(c):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || lex.IsAlphaChar(c):
+		return p.parseToken()
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		return p.parseBoolean()
+	case p.empty():
+		return nil, parseError(p.offset, "expected an item, found end of input")
+	default:
+		return nil, parseError(p.offset, "expected an item, found %q", c)
+	}
+}
+
+func (p *parser) parseParameters() (Params, error) {
+	// RFC 8941, 4.2.3.2. Parsing Parameters
+	var params Params
+	for p.peek() == ';' {
+		p.offset++ // skip the semicolon
+		p.skipSpaces()
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		var value any = true
+		if p.peek() == '=' {
+			p.offset++ // skip the equal sign
+			value, err = p.parseBareItem()
+			if err != nil {
+				return nil, err
+			}
+		}
+		params.Set(key, value)
+	}
+	return params, nil
+}
+
+func isKeyChar(c byte) bool {
+	// RFC 8941, 3.1.2. Parameters
+	return (c >= 'a' && c <= 'z') || lex.IsDigitChar(c) || c == '_' || c == '-' || c == '.' || c == '*'
+}
+
+func (p *parser) parseKey() (string, error) {
+	// RFC 8941, 4.2.3.3. Parsing a Key
+	if c := p.peek(); c != '*' && (c < 'a' || c > 'z') {
+		return "", parseError(p.offset, "expected a key, found %q", c)
+	}
+	start := p.offset
+	for !p.empty() && isKeyChar(p.peek()) {
+		p.offset++
+	}
+	return p.input[start:p.offset], nil
+}
+
+func (p *parser) parseNumber() (any, error) {
+	// RFC 8941, 4.2.4. Parsing an Integer or Decimal
+	start := p.offset
+	if p.peek() == '-' {
+		p.offset++
+	}
+	if !lex.IsDigitChar(p.peek()) {
+		return nil, parseError(p.offset, "expected a digit, found %q", p.peek())
+	}
+
+	digits := p.offset
+	point := -1
+	for !p.empty() {
+		c := p.peek()
+		if lex.IsDigitChar(c) {
+			p.offset++
+		} else if c == '.' && point < 0 {
+			if p.offset-digits > 12 {
+				return nil, parseError(p.offset, "decimal has more than 12 integer digits")
+			}
+			point = p.offset
+			p.offset++
+		} else {
+			break
+		}
+		if point < 0 && p.offset-digits > 15 {
+			return nil, parseError(p.offset, "integer has more than 15 digits")
+		}
+		if point >= 0 && p.offset-digits > 16 {
+			return nil, parseError(p.offset, "decimal has more than 16 characters")
+		}
+	}
+
+	text := p.input[start:p.offset]
+	if point < 0 {
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, parseError(start, "invalid integer %q", text)
+		}
+		return n, nil
+	}
+
+	if fraction := p.offset - point - 1; fraction == 0 {
+		return nil, parseError(p.offset, "decimal ends with a point")
+	} else if fraction > 3 {
+		return nil, parseError(point, "decimal has more than 3 fractional digits")
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, parseError(start, "invalid decimal %q", text)
+	}
+	return f, nil
+}
+
+func (p *parser) parseString() (string, error) {
+	// RFC 8941, 4.2.5. Parsing a String
+	p.offset++ // skip the opening quote
+
+	var b strings.Builder
+	for !p.empty() {
+		c := p.peek()
+		p.offset++
+		switch {
+		case c == '\\':
+			if p.empty() {
+				return "", parseError(p.offset, "unterminated escape")
+			}
+			if n := p.peek(); n != '"' && n != '\\' {
+				return "", parseError(p.offset, "invalid escape %q", n)
+			}
+			b.WriteByte(p.peek())
+			p.offset++
+		case c == '"':
+			return b.String(), nil
+		case c < 0x20 || c > 0x7E:
+			return "", parseError(p.offset-1, "invalid character %q in string", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", parseError(p.offset, "unterminated string")
+}
+
+func isSFTokenChar(c byte) bool {
+	// RFC 8941, 3.3.4. Tokens
+	return lex.IsTokenChar(c) || c == ':' || c == '/'
+}
+
+func (p *parser) parseToken() (Token, error) {
+	// RFC 8941, 4.2.6. Parsing a Token
+	start := p.offset
+	p.offset++ // the first character has already been checked
+	for !p.empty() && isSFTokenChar(p.peek()) {
+		p.offset++
+	}
+	return Token(p.input[start:p.offset]), nil
+}
+
+func isBase64Char(c byte) bool {
+	return lex.IsAlphaChar(c) || lex.IsDigitChar(c) || c == '+' || c == '/' || c == '='
+}
+
+func (p *parser) parseByteSequence() ([]byte, error) {
+	// RFC 8941, 4.2.7. Parsing a Byte Sequence
+	p.offset++ // skip the opening colon
+	start := p.offset
+	for !p.empty() && isBase64Char(p.peek()) {
+		p.offset++
+	}
+	if p.peek() != ':' {
+		return nil, parseError(p.offset, "unterminated byte sequence")
+	}
+	// Padding is optional, since parsers should not fail when it is missing.
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(p.input[start:p.offset], "="))
+	if err != nil {
+		return nil, parseError(start, "invalid base64 in byte sequence")
+	}
+	p.offset++ // skip the closing colon
+	return data, nil
+}
+
+func (p *parser) parseBoolean() (bool, error) {
+	// RFC 8941, 4.2.8. Parsing a Boolean
+	p.offset++ // skip the question mark
+	switch p.peek() {
+	case '1':
+		p.offset++
+		return true, nil
+	case '0':
+		p.offset++
+		return false, nil
+	default:
+		return false, parseError(p.offset, "expected a boolean, found %q", p.peek())
+	}
+}
@@ -0,0 +1,222 @@
+package sfv
+
+import (
+	"encoding/base64"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+// Serializes an Item into a field value.
+func SerializeItem(item Item) (string, error) {
+	var b strings.Builder
+	if err := serializeItem(&b, item); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Serializes a List into a field value.
+func SerializeList(list List) (string, error) {
+	// RFC 8941, 4.1.1. Serializing a List
+	var b strings.Builder
+	for i, member := range list {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := serializeMember(&b, member); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// Serializes a Dictionary into a field value.
+func SerializeDictionary(dict Dictionary) (string, error) {
+	// RFC 8941, 4.1.2. Serializing a Dictionary
+	var b strings.Builder
+	for i, e := range dict {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := serializeKey(&b, e.Key); err != nil {
+			return "", err
+		}
+		if item, ok := e.Member.(Item); ok && item.Value == true {
+			if err := serializeParameters(&b, item.Params); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte('=')
+		if err := serializeMember(&b, e.Member); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func serializeMember(b *strings.Builder, member Member) error {
+	switch m := member.(type) {
+	case Item:
+		return serializeItem(b, m)
+	case InnerList:
+		return serializeInnerList(b, m)
+	default:
+		return valueError("unsupported member %T", member)
+	}
+}
+
+func serializeInnerList(b *strings.Builder, list InnerList) error {
+	// RFC 8941, 4.1.1.1. Serializing an Inner List
+	b.WriteByte('(')
+	for i, item := range list.Items {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if err := serializeItem(b, item); err != nil {
+			return err
+		}
+	}
+	b.WriteByte(')')
+	return serializeParameters(b, list.Params)
+}
+
+func serializeParameters(b *strings.Builder, params Params) error {
+	// RFC 8941, 4.1.1.2. Serializing Parameters
+	for _, param := range params {
+		b.WriteByte(';')
+		if err := serializeKey(b, param.Key); err != nil {
+			return err
+		}
+		if param.Value != true {
+			b.WriteByte('=')
+			if err := serializeBareItem(b, param.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func serializeKey(b *strings.Builder, key string) error {
+	// RFC 8941, 4.1.1.3. Serializing a Key
+	if !isKey(key) {
+		return valueError("invalid key %q", key)
+	}
+	b.WriteString(key)
+	return nil
+}
+
+func isKey(key string) bool {
+	if len(key) == 0 || (key[0] != '*' && (key[0] < 'a' || key[0] > 'z')) {
+		return false
+	}
+	for i := 1; i < len(key); i++ {
+		if !isKeyChar(key[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func serializeItem(b *strings.Builder, item Item) error {
+	// RFC 8941, 4.1.3. Serializing an Item
+	if err := serializeBareItem(b, item.Value); err != nil {
+		return err
+	}
+	return serializeParameters(b, item.Params)
+}
+
+func serializeBareItem(b *strings.Builder, value any) error {
+	// RFC 8941, 4.1.3.1. Serializing a Bare Item
+	switch v := value.(type) {
+	case int:
+		return serializeInteger(b, int64(v))
+	case int64:
+		return serializeInteger(b, v)
+	case float64:
+		return serializeDecimal(b, v)
+	case string:
+		return serializeString(b, v)
+	case Token:
+		return serializeToken(b, v)
+	case []byte:
+		b.WriteByte(':')
+		b.WriteString(base64.StdEncoding.EncodeToString(v))
+		b.WriteByte(':')
+		return nil
+	case bool:
+		if v {
+			b.WriteString("?1")
+		} else {
+			b.WriteString("?0")
+		}
+		return nil
+	default:
+		return valueError("unsupported bare item %T", value)
+	}
+}
+
+func serializeInteger(b *strings.Builder, v int64) error {
+	// RFC 8941, 4.1.4. Serializing an Integer
+	if v < -999_999_999_999_999 || v > 999_999_999_999_999 {
+		return valueError("integer %d out of range", v)
+	}
+	b.WriteString(strconv.FormatInt(v, 10))
+	return nil
+}
+
+func serializeDecimal(b *strings.Builder, v float64) error {
+	// RFC 8941, 4.1.5. Serializing a Decimal
+	v = math.RoundToEven(v*1000) / 1000
+	if math.IsNaN(v) || math.Abs(v) >= 1e12 {
+		return valueError("decimal %v out of range", v)
+	}
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	b.WriteString(s)
+	if !strings.Contains(s, ".") {
+		b.WriteString(".0")
+	}
+	return nil
+}
+
+func serializeString(b *strings.Builder, v string) error {
+	// RFC 8941, 4.1.6. Serializing a String
+	b.WriteByte('"')
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c < 0x20 || c > 0x7E {
+			return valueError("invalid character %q in string", c)
+		}
+		if c == '"' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return nil
+}
+
+func isToken(v string) bool {
+	if len(v) == 0 || (v[0] != '*' && !lex.IsAlphaChar(v[0])) {
+		return false
+	}
+	for i := 1; i < len(v); i++ {
+		if !isSFTokenChar(v[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func serializeToken(b *strings.Builder, v Token) error {
+	// RFC 8941, 4.1.7. Serializing a Token
+	if !isToken(string(v)) {
+		return valueError("invalid token %q", string(v))
+	}
+	b.WriteString(string(v))
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package sfv implements Structured Field Values for HTTP, as defined by
+// RFC 8941.
+//
+// Bare item values are represented by Go values as follows:
+//
+//	Integer        int64
+//	Decimal        float64
+//	String         string
+//	Token          Token
+//	Byte Sequence  []byte
+//	Boolean        bool
+//
+// Serialization also accepts int for integers.
+package sfv
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// Structured field is syntactically invalid.
+	ErrInvalidField = errors.New("invalid structured field")
+	// Value can not be represented as a structured field.
+	ErrInvalidValue = errors.New("invalid structured field value")
+)
+
+// A Token is a short textual word, distinct from a String.
+type Token string
+
+// A Param is a single parameter of an item or inner list.
+type Param struct {
+	Key   string
+	Value any
+}
+
+// Parameters are an ordered map of keys to bare items.
+type Params []Param
+
+// Returns the value of the parameter with the key.
+func (p Params) Get(key string) (any, bool) {
+	for _, e := range p {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Sets the value of the parameter with the key, replacing any existing value
+// in place so the order of the parameters is preserved.
+func (p *Params) Set(key string, value any) {
+	for i, e := range *p {
+		if e.Key == key {
+			(*p)[i].Value = value
+			return
+		}
+	}
+	*p = append(*p, Param{Key: key, Value: value})
+}
+
+// A Member of a list or dictionary, which is either an Item or an InnerList.
+type Member interface {
+	member()
+}
+
+// An Item is a bare item value with parameters.
+type Item struct {
+	Value  any
+	Params Params
+}
+
+func (Item) member() {}
+
+// An InnerList is a list of items with parameters of its own.
+type InnerList struct {
+	Items  []Item
+	Params Params
+}
+
+func (InnerList) member() {}
+
+// A List is a sequence of members.
+type List []Member
+
+// A DictMember is a single entry in a dictionary.
+type DictMember struct {
+	Key    string
+	Member Member
+}
+
+// A Dictionary is an ordered map of keys to members.
+type Dictionary []DictMember
+
+// Returns the member with the key.
+func (d Dictionary) Get(key string) (Member, bool) {
+	for _, e := range d {
+		if e.Key == key {
+			return e.Member, true
+		}
+	}
+	return nil, false
+}
+
+// Sets the member with the key, replacing any existing member in place so the
+// order of the dictionary is preserved.
+func (d *Dictionary) Set(key string, member Member) {
+	for i, e := range *d {
+		if e.Key == key {
+			(*d)[i].Member = member
+			return
+		}
+	}
+	*d = append(*d, DictMember{Key: key, Member: member})
+}
+
+// Describes a problem found while parsing, wrapping ErrInvalidField.
+func parseError(offset int, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidField, fmt.Sprintf(format, args...), offset)
+}
+
+// Describes a value which can not be serialized, wrapping ErrInvalidValue.
+func valueError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidValue, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,176 @@
+package sfv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseItem(t *testing.T) {
+	testCases := []struct {
+		name   string
+		value  string
+		result Item
+	}{
+		{"Integer", "42", Item{Value: int64(42)}},
+		{"Negative integer", "-999999999999999", Item{Value: int64(-999999999999999)}},
+		{"Decimal", "4.5", Item{Value: 4.5}},
+		{"String", `"hello \"world\""`, Item{Value: `hello "world"`}},
+		{"Token", "text/html", Item{Value: Token("text/html")}},
+		{"Byte sequence", ":aGVsbG8=:", Item{Value: []byte("hello")}},
+		{"Unpadded byte sequence", ":aGVsbG8:", Item{Value: []byte("hello")}},
+		{"Boolean", "?0", Item{Value: false}},
+		{"Parameters", "abc;a=1;b=?0;c", Item{Value: Token("abc"), Params: Params{{"a", int64(1)}, {"b", false}, {"c", true}}}},
+		{"Duplicate parameter", "abc;a=1;b=2;a=3", Item{Value: Token("abc"), Params: Params{{"a", int64(3)}, {"b", int64(2)}}}},
+		{"Surrounding spaces", "  1  ", Item{Value: int64(1)}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := ParseItem(testCase.value)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\" for %s", err, testCase.value)
+			} else if !reflect.DeepEqual(result, testCase.result) {
+				t.Errorf("Invalid item, got %#v, expected %#v for %s", result, testCase.result, testCase.value)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+	}{
+		{"Empty", ""},
+		{"Integer too long", "1234567890123456"},
+		{"Decimal too long", "1234567890123.0"},
+		{"Too many fractional digits", "1.2345"},
+		{"Trailing point", "1."},
+		{"Unterminated string", `"abc`},
+		{"Invalid escape", `"\a"`},
+		{"Non-ASCII", "\"caf\xc3\xa9\""},
+		{"Invalid boolean", "?2"},
+		{"Invalid byte sequence", ":abc"},
+		{"Truncated byte sequence", ":aGVsbG8aa:"},
+		{"Uppercase key", "a;B=1"},
+		{"Trailing characters", "a b"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, err := ParseItem(testCase.value); !errors.Is(err, ErrInvalidField) {
+				t.Errorf("Expected an invalid field error for %s, got %v", testCase.value, err)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	testCases := []struct {
+		name       string
+		value      string
+		result     List
+		serialized string
+	}{
+		{"Empty", "", List{}, ""},
+		{"Tokens", "sugar, tea,rum", List{Item{Value: Token("sugar")}, Item{Value: Token("tea")}, Item{Value: Token("rum")}}, "sugar, tea, rum"},
+		{"Inner lists", `("foo" "bar");lvl=5, ("baz");lvl=1, ()`, List{
+			InnerList{Items: []Item{{Value: "foo"}, {Value: "bar"}}, Params: Params{{"lvl", int64(5)}}},
+			InnerList{Items: []Item{{Value: "baz"}}, Params: Params{{"lvl", int64(1)}}},
+			InnerList{Items: []Item{}},
+		}, `("foo" "bar");lvl=5, ("baz");lvl=1, ()`},
+		{"Media types", "text/html;q=1.0, application/json;q=0.5", List{
+			Item{Value: Token("text/html"), Params: Params{{"q", 1.0}}},
+			Item{Value: Token("application/json"), Params: Params{{"q", 0.5}}},
+		}, "text/html;q=1.0, application/json;q=0.5"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := ParseList(testCase.value)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\" for %s", err, testCase.value)
+			} else if !reflect.DeepEqual(result, testCase.result) {
+				t.Fatalf("Invalid list, got %#v, expected %#v for %s", result, testCase.result, testCase.value)
+			}
+			serialized, err := SerializeList(result)
+			if err != nil {
+				t.Fatalf("Unexpected error \"%s\" for %s", err, testCase.value)
+			} else if serialized != testCase.serialized {
+				t.Errorf("Invalid serialization, got %s, expected %s", serialized, testCase.serialized)
+			}
+		})
+	}
+
+	for _, value := range []string{"a,", "a,,b", "(a b", "(a,b)"} {
+		if _, err := ParseList(value); !errors.Is(err, ErrInvalidField) {
+			t.Errorf("Expected an invalid field error for %s, got %v", value, err)
+		}
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	value := `en="Applepie", da=:w4ZibGV0w6ZydGU=:, a=1, b, c;x=y, a=2`
+	dict, err := ParseDictionary(value)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\" for %s", err, value)
+	}
+
+	expected := Dictionary{
+		{"en", Item{Value: "Applepie"}},
+		{"da", Item{Value: []byte("\xc3\x86blet\xc3\xa6rte")}},
+		{"a", Item{Value: int64(2)}},
+		{"b", Item{Value: true}},
+		{"c", Item{Value: true, Params: Params{{"x", Token("y")}}}},
+	}
+	if !reflect.DeepEqual(dict, expected) {
+		t.Fatalf("Invalid dictionary, got %#v, expected %#v", dict, expected)
+	}
+	if member, ok := dict.Get("a"); !ok || !reflect.DeepEqual(member, Item{Value: int64(2)}) {
+		t.Errorf("Invalid member, got %#v", member)
+	}
+
+	serialized, err := SerializeDictionary(dict)
+	if err != nil {
+		t.Fatalf("Unexpected error \"%s\"", err)
+	} else if e := `en="Applepie", da=:w4ZibGV0w6ZydGU=:, a=2, b, c;x=y`; serialized != e {
+		t.Errorf("Invalid serialization, got %s, expected %s", serialized, e)
+	}
+}
+
+func TestSerializeItem(t *testing.T) {
+	testCases := []struct {
+		name   string
+		value  Item
+		result string
+		err    bool
+	}{
+		{"Integer", Item{Value: 7}, "7", false},
+		{"Decimal rounding", Item{Value: 1.23456}, "1.235", false},
+		{"Whole decimal", Item{Value: 2.0}, "2.0", false},
+		{"String escapes", Item{Value: `a"b\c`}, `"a\"b\\c"`, false},
+		{"Boolean parameter", Item{Value: Token("a"), Params: Params{{"b", true}, {"c", false}}}, "a;b;c=?0", false},
+		{"Integer out of range", Item{Value: int64(1e15)}, "", true},
+		{"Decimal out of range", Item{Value: 1e12}, "", true},
+		{"Invalid token", Item{Value: Token("1a")}, "", true},
+		{"Invalid key", Item{Value: 1, Params: Params{{"A", 1}}}, "", true},
+		{"Non-ASCII string", Item{Value: "café"}, "", true},
+		{"Unsupported value", Item{Value: struct{}{}}, "", true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := SerializeItem(testCase.value)
+			if testCase.err {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("Expected an invalid value error for %#v, got %v", testCase.value, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error \"%s\" for %#v", err, testCase.value)
+			} else if result != testCase.result {
+				t.Errorf("Invalid serialization, got %s, expected %s", result, testCase.result)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package sniff
+
+import (
+	"errors"
+	"strings"
+
+	mime "github.com/bww/go-mime/v1"
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+var ErrInvalidMIMEType = errors.New("invalid MIME type")
+
+// Parameter is a MIME type parameter. Names are lower case; values are kept
+// as they were given.
+type Parameter struct {
+	Name, Value string
+}
+
+// MIMEType is a MIME type record as defined by the WHATWG MIME Sniffing
+// Standard, section 4.1. Unlike the RFC grammar used by mime.Parse, the
+// standard keeps parameters in order, ignores invalid parameters instead of
+// failing, keeps the first of duplicate parameters and tolerates unterminated
+// quoted strings.
+//
+// Strings are handled as browsers handle header values: each byte is a code
+// point, so values may contain bytes 0x80 to 0xFF.
+type MIMEType struct {
+	Type       string
+	Subtype    string
+	Parameters []Parameter
+}
+
+// Returns the type and subtype without parameters.
+func (m MIMEType) Essence() string {
+	return m.Type + "/" + m.Subtype
+}
+
+// Returns the value of the named parameter.
+func (m MIMEType) Param(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for _, e := range m.Parameters {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// Replaces the value of a parameter, or adds it if it is not set.
+func (m *MIMEType) setParam(name, value string) {
+	for i, e := range m.Parameters {
+		if e.Name == name {
+			m.Parameters[i].Value = value
+			return
+		}
+	}
+	m.Parameters = append(m.Parameters, Parameter{Name: name, Value: value})
+}
+
+func (m MIMEType) copy() MIMEType {
+	m.Parameters = append([]Parameter(nil), m.Parameters...)
+	return m
+}
+
+// Parses a MIME type, per the WHATWG MIME Sniffing Standard, section 4.4.
+func Parse(s string) (MIMEType, error) {
+	s = strings.Trim(s, httpWhitespace)
+
+	x := strings.IndexByte(s, '/')
+	if x < 0 {
+		return MIMEType{}, ErrInvalidMIMEType
+	}
+	t := s[:x]
+	if !lex.IsToken(t) {
+		return MIMEType{}, ErrInvalidMIMEType
+	}
+	s = s[x+1:]
+
+	x = strings.IndexByte(s, ';')
+	if x < 0 {
+		x = len(s)
+	}
+	sub := strings.TrimRight(s[:x], httpWhitespace)
+	if !lex.IsToken(sub) {
+		return MIMEType{}, ErrInvalidMIMEType
+	}
+	s = s[x:]
+
+	m := MIMEType{Type: strings.ToLower(t), Subtype: strings.ToLower(sub)}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s[1:], httpWhitespace) // skip the semicolon
+
+		x = strings.IndexAny(s, ";=")
+		if x < 0 {
+			x = len(s)
+		}
+		name := strings.ToLower(s[:x])
+		s = s[x:]
+		if len(s) > 0 {
+			if s[0] == ';' {
+				continue
+			}
+			s = s[1:] // skip the equals sign
+		}
+		if len(s) == 0 {
+			break
+		}
+
+		var value string
+		if s[0] == '"' {
+			value, s = collectQuotedString(s)
+			if x = strings.IndexByte(s, ';'); x >= 0 {
+				s = s[x:]
+			} else {
+				s = ""
+			}
+		} else {
+			if x = strings.IndexByte(s, ';'); x < 0 {
+				x = len(s)
+			}
+			value, s = strings.TrimRight(s[:x], httpWhitespace), s[x:]
+			if value == "" {
+				continue
+			}
+		}
+
+		if lex.IsToken(name) && isQuotedStringTokenString(value) {
+			if _, ok := m.Param(name); !ok {
+				m.Parameters = append(m.Parameters, Parameter{Name: name, Value: value})
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Collects an HTTP quoted string and extracts its value, per the WHATWG Fetch
+// Standard, section 2.2. The string starts with a quote; the remaining input
+// is returned.
+func collectQuotedString(s string) (string, string) {
+	b := &strings.Builder{}
+	s = s[1:]
+	for {
+		x := strings.IndexAny(s, "\"\\")
+		if x < 0 {
+			b.WriteString(s)
+			return b.String(), ""
+		}
+		b.WriteString(s[:x])
+		c := s[x]
+		s = s[x+1:]
+		if c == '"' {
+			return b.String(), s
+		}
+		if len(s) == 0 {
+			b.WriteByte('\\')
+			return b.String(), ""
+		}
+		b.WriteByte(s[0])
+		s = s[1:]
+	}
+}
+
+// Serializes the MIME type, per the WHATWG MIME Sniffing Standard, section
+// 4.5.
+func (m MIMEType) String() string {
+	b := &strings.Builder{}
+	b.WriteString(m.Essence())
+	for _, e := range m.Parameters {
+		b.WriteByte(';')
+		b.WriteString(e.Name)
+		b.WriteByte('=')
+		if lex.IsToken(e.Value) {
+			b.WriteString(e.Value)
+			continue
+		}
+		b.WriteByte('"')
+		for i := 0; i < len(e.Value); i++ {
+			if c := e.Value[i]; c == '"' || c == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(e.Value[i])
+		}
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// Converts the MIME type to a mime.Type, in its serialized form.
+func (m MIMEType) Mime() mime.Type {
+	return mime.Type(m.String())
+}
+
+// Parses a mime.Type as a MIME type record.
+func FromType(t mime.Type) (MIMEType, error) {
+	return Parse(t.String())
+}
+
+// MIME type groups, per the WHATWG MIME Sniffing Standard, section 4.6.
+
+// Reports whether the MIME type is an image type.
+func (m MIMEType) IsImage() bool {
+	return m.Type == "image"
+}
+
+// Reports whether the MIME type is an audio or video type.
+func (m MIMEType) IsAudioOrVideo() bool {
+	return m.Type == "audio" || m.Type == "video" || m.Essence() == "application/ogg"
+}
+
+// Reports whether the MIME type is a font type.
+func (m MIMEType) IsFont() bool {
+	if m.Type == "font" {
+		return true
+	}
+	switch m.Essence() {
+	case "application/font-cff", "application/font-off", "application/font-sfnt", "application/font-ttf",
+		"application/font-woff", "application/vnd.ms-fontobject", "application/vnd.ms-opentype":
+		return true
+	}
+	return false
+}
+
+// Reports whether the MIME type is a zip-based type.
+func (m MIMEType) IsZipBased() bool {
+	return strings.HasSuffix(m.Subtype, "+zip") || m.Essence() == "application/zip"
+}
+
+// Reports whether the MIME type is an archive type.
+func (m MIMEType) IsArchive() bool {
+	switch m.Essence() {
+	case "application/x-rar-compressed", "application/zip", "application/x-gzip":
+		return true
+	}
+	return false
+}
+
+// Reports whether the MIME type is an XML type.
+func (m MIMEType) IsXML() bool {
+	return strings.HasSuffix(m.Subtype, "+xml") || m.Essence() == "text/xml" || m.Essence() == "application/xml"
+}
+
+// Reports whether the MIME type is text/html.
+func (m MIMEType) IsHTML() bool {
+	return m.Essence() == "text/html"
+}
+
+// Reports whether the MIME type is a scriptable type: an XML type, HTML or
+// PDF.
+func (m MIMEType) IsScriptable() bool {
+	return m.IsXML() || m.IsHTML() || m.Essence() == "application/pdf"
+}
+
+// Reports whether the MIME type is a JavaScript type.
+func (m MIMEType) IsJavaScript() bool {
+	switch m.Essence() {
+	case "application/ecmascript", "application/javascript", "application/x-ecmascript", "application/x-javascript",
+		"text/ecmascript", "text/javascript", "text/javascript1.0", "text/javascript1.1", "text/javascript1.2",
+		"text/javascript1.3", "text/javascript1.4", "text/javascript1.5", "text/jscript", "text/livescript",
+		"text/x-ecmascript", "text/x-javascript":
+		return true
+	}
+	return false
+}
+
+// Reports whether the MIME type is a JSON type.
+func (m MIMEType) IsJSON() bool {
+	return strings.HasSuffix(m.Subtype, "+json") || m.Essence() == "application/json" || m.Essence() == "text/json"
+}
+
+// HTTP whitespace, per the WHATWG Fetch Standard.
+const httpWhitespace = "\t\n\r "
+
+func isQuotedStringTokenString(s string) bool {
+	// WHATWG MIME Sniffing, 2.1. HTTP quoted-string token code points
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c != '\t' && (c < 0x20 || c == 0x7f) {
+			return false
+		}
+	}
+	return true
+}
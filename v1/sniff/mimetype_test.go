@@ -0,0 +1,108 @@
+package sniff
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input  string
+		result string
+	}{
+		{"text/html;charset=gbk", "text/html;charset=gbk"},
+		{"TEXT/HTML;CHARSET=GBK", "text/html;charset=GBK"},
+		{" text/html ; charset=gbk ", "text/html;charset=gbk"},
+		{"text/html;charset=gbk;charset=windows-1255", "text/html;charset=gbk"},
+		{"text/html;charset=\"gbk\"", "text/html;charset=gbk"},
+		{"text/html;charset=\"gbk", "text/html;charset=gbk"},
+		{"text/html;charset=\"gbk\"x", "text/html;charset=gbk"},
+		{"text/html;charset=\"\\\"gbk\\\"\"", "text/html;charset=\"\\\"gbk\\\"\""},
+		{"text/html;charset=\"gbk\\", "text/html;charset=\"gbk\\\\\""},
+		{"text/html;charset=\"\"", "text/html;charset=\"\""},
+		{"text/html;charset=", "text/html"},
+		{"text/html;charset", "text/html"},
+		{"text/html;;;;charset=gbk", "text/html;charset=gbk"},
+		{"text/html;charset= gbk", "text/html;charset=\" gbk\""},
+		{"text/html;charset=gbk ;", "text/html;charset=gbk"},
+		{"text/html;test=ÿ;charset=gbk", "text/html;test=\"ÿ\";charset=gbk"},
+		{"text/html;a]=bar;b[=bar;c=bar", "text/html;c=bar"},
+		{"text/html;valid=\";charset=gbk", "text/html;valid=\";charset=gbk\""},
+		{"x/x;x=\"\t !\\\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\\\]^_`abcdefghijklmnopqrstuvwxyz{|}~\"", "x/x;x=\"\t !\\\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\\\]^_`abcdefghijklmnopqrstuvwxyz{|}~\""},
+		{"application/vnd.acme+json;version=2", "application/vnd.acme+json;version=2"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			m, err := Parse(testCase.input)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\" for %s", err, testCase.input)
+			} else if m.String() != testCase.result {
+				t.Errorf("Invalid MIME type, got %s, expected %s for %s", m.String(), testCase.result, testCase.input)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []string{
+		"",
+		"\t",
+		"/",
+		"bogus",
+		"bogus/",
+		"/bogus",
+		"bogus/ bogus",
+		"(/)",
+		"text/html(;doesnot=matter",
+		"{/}",
+		"ÿ/ÿ",
+		"text /html",
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			if m, err := Parse(testCase); err == nil {
+				t.Errorf("Expected an error for %q, got %s", testCase, m)
+			}
+		})
+	}
+}
+
+func TestGroups(t *testing.T) {
+	testCases := []struct {
+		input                           string
+		image, av, font, zip, archive   bool
+		xml, html, scriptable, js, json bool
+	}{
+		{input: "image/png", image: true},
+		{input: "video/mp4", av: true},
+		{input: "application/ogg", av: true},
+		{input: "font/woff2", font: true},
+		{input: "application/vnd.ms-fontobject", font: true},
+		{input: "application/zip", zip: true, archive: true},
+		{input: "application/epub+zip", zip: true},
+		{input: "application/x-gzip", archive: true},
+		{input: "image/svg+xml", image: true, xml: true, scriptable: true},
+		{input: "text/html;charset=utf-8", html: true, scriptable: true},
+		{input: "application/pdf", scriptable: true},
+		{input: "text/javascript", js: true},
+		{input: "application/x-javascript", js: true},
+		{input: "application/problem+json", json: true},
+		{input: "text/plain"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			m, err := Parse(testCase.input)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\" for %s", err, testCase.input)
+				return
+			}
+			got := [...]bool{m.IsImage(), m.IsAudioOrVideo(), m.IsFont(), m.IsZipBased(), m.IsArchive(), m.IsXML(), m.IsHTML(), m.IsScriptable(), m.IsJavaScript(), m.IsJSON()}
+			expect := [...]bool{testCase.image, testCase.av, testCase.font, testCase.zip, testCase.archive, testCase.xml, testCase.html, testCase.scriptable, testCase.js, testCase.json}
+			if got != expect {
+				t.Errorf("Unexpected groups %v, expected %v for %s", got, expect, testCase.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package sniff
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// A byte pattern, per the WHATWG MIME Sniffing Standard, section 6.
+type pattern struct {
+	pattern []byte
+	mask    []byte
+	ignored string // leading bytes to skip
+	tagEnd  bool   // the pattern must be followed by a tag-terminating byte
+	result  mime.Type
+}
+
+// Whitespace bytes ignored before patterns in the standard.
+const whitespaceBytes = "\t\n\x0c\r "
+
+// Matches the pattern against the input, per the WHATWG MIME Sniffing
+// Standard, section 6, "pattern matching algorithm".
+func (p pattern) match(input []byte) bool {
+	if len(input) < len(p.pattern) {
+		return false
+	}
+	s := 0
+	for s < len(input) && indexByte(p.ignored, input[s]) {
+		s++
+	}
+	for i := range p.pattern {
+		if s >= len(input) || input[s]&p.mask[i] != p.pattern[i] {
+			return false
+		}
+		s++
+	}
+	if p.tagEnd {
+		// 7.1, a tag-terminating byte is a space or '>'
+		return s < len(input) && (input[s] == ' ' || input[s] == '>')
+	}
+	return true
+}
+
+func indexByte(s string, c byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the result of the first of the patterns which matches.
+func matchPatterns(patterns []pattern, input []byte) (mime.Type, bool) {
+	for _, e := range patterns {
+		if e.match(input) {
+			return e.result, true
+		}
+	}
+	return mime.Invalid, false
+}
+
+// Creates a pattern which matches bytes exactly.
+func exact(s string, result mime.Type) pattern {
+	return pattern{pattern: []byte(s), mask: bytes.Repeat([]byte{0xff}, len(s)), result: result}
+}
+
+// Creates a pattern whose mask is 0x00 where the pattern has a '?'.
+func masked(s string, result mime.Type) pattern {
+	p := pattern{pattern: []byte(s), mask: make([]byte, len(s)), result: result}
+	for i := range p.pattern {
+		if p.pattern[i] == '?' {
+			p.pattern[i] = 0
+		} else {
+			p.mask[i] = 0xff
+		}
+	}
+	return p
+}
+
+// Creates an HTML pattern: case-insensitive, after any whitespace and
+// followed by a tag-terminating byte.
+func htmlTag(s string) pattern {
+	p := pattern{pattern: []byte(s), mask: make([]byte, len(s)), ignored: whitespaceBytes, tagEnd: true, result: "text/html"}
+	for i, c := range p.pattern {
+		if c >= 'A' && c <= 'Z' {
+			p.mask[i] = 0xdf
+		} else {
+			p.mask[i] = 0xff
+		}
+	}
+	return p
+}
+
+// 6.1. Matching an image type pattern
+var imagePatterns = []pattern{
+	exact("\x00\x00\x01\x00", "image/x-icon"),
+	exact("\x00\x00\x02\x00", "image/x-icon"),
+	exact("BM", "image/bmp"),
+	exact("GIF87a", "image/gif"),
+	exact("GIF89a", "image/gif"),
+	masked("RIFF????WEBPVP", "image/webp"),
+	exact("\x89PNG\r\n\x1a\n", "image/png"),
+	exact("\xff\xd8\xff", "image/jpeg"),
+}
+
+// 6.2. Matching an audio or video type pattern
+var audioVideoPatterns = []pattern{
+	exact(".snd", "audio/basic"),
+	masked("FORM????AIFF", "audio/aiff"),
+	exact("ID3", "audio/mpeg"),
+	exact("OggS\x00", "application/ogg"),
+	exact("MThd\x00\x00\x00\x06", "audio/midi"),
+	masked("RIFF????AVI ", "video/avi"),
+	masked("RIFF????WAVE", "audio/wave"),
+}
+
+// 6.3. Matching a font type pattern
+var fontPatterns = []pattern{
+	{
+		pattern: append(make([]byte, 34), 'L', 'P'),
+		mask:    append(make([]byte, 34), 0xff, 0xff),
+		result:  "application/vnd.ms-fontobject",
+	},
+	exact("\x00\x01\x00\x00", "font/ttf"),
+	exact("OTTO", "font/otf"),
+	exact("ttcf", "font/collection"),
+	exact("wOFF", "font/woff"),
+	exact("wOF2", "font/woff2"),
+}
+
+// 6.4. Matching an archive type pattern
+var archivePatterns = []pattern{
+	exact("\x1f\x8b\x08", "application/x-gzip"),
+	exact("PK\x03\x04", "application/zip"),
+	exact("Rar \x1a\x07\x00", "application/x-rar-compressed"),
+}
+
+// Identifies an image from its header, per section 6.1.
+func Image(header []byte) (mime.Type, bool) {
+	return matchPatterns(imagePatterns, header)
+}
+
+// Identifies audio or video from its header, per section 6.2.
+func AudioVideo(header []byte) (mime.Type, bool) {
+	if t, ok := matchPatterns(audioVideoPatterns, header); ok {
+		return t, true
+	}
+	switch {
+	case isMP4(header):
+		return "video/mp4", true
+	case isWebM(header):
+		return "video/webm", true
+	case isMP3WithoutID3(header):
+		return "audio/mpeg", true
+	}
+	return mime.Invalid, false
+}
+
+// Identifies a font from its header, per section 6.3.
+func Font(header []byte) (mime.Type, bool) {
+	return matchPatterns(fontPatterns, header)
+}
+
+// Identifies an archive from its header, per section 6.4.
+func Archive(header []byte) (mime.Type, bool) {
+	return matchPatterns(archivePatterns, header)
+}
+
+// 6.2.1. Signature for MP4
+func isMP4(b []byte) bool {
+	if len(b) < 12 {
+		return false
+	}
+	size := int(binary.BigEndian.Uint32(b))
+	if len(b) < size || size%4 != 0 {
+		return false
+	}
+	if string(b[4:8]) != "ftyp" {
+		return false
+	}
+	if string(b[8:11]) == "mp4" {
+		return true
+	}
+	for n := 16; n < size; n += 4 {
+		if string(b[n:n+3]) == "mp4" {
+			return true
+		}
+	}
+	return false
+}
+
+// 6.2.2. Signature for WebM
+func isWebM(b []byte) bool {
+	if len(b) < 4 || string(b[:4]) != "\x1a\x45\xdf\xa3" {
+		return false
+	}
+	for i := 4; i < len(b) && i < 38; i++ {
+		if i+1 < len(b) && b[i] == 0x42 && b[i+1] == 0x82 {
+			i += 2
+			if i >= len(b) {
+				break
+			}
+			i += vintSize(b[i:])
+			if i >= len(b)-4 {
+				break
+			}
+			if matchPadded(b, "webm", i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the size of the variable length integer at the start of b, per
+// section 6.2.2, "parse a vint".
+func vintSize(b []byte) int {
+	mask, n := byte(0x80), 1
+	for n < 8 && n < len(b) && b[0]&mask == 0 {
+		mask >>= 1
+		n++
+	}
+	return n
+}
+
+// Matches a padded sequence: the pattern after any number of zero bytes.
+func matchPadded(b []byte, p string, offset int) bool {
+	for offset < len(b) && b[offset] == 0 {
+		offset++
+	}
+	return len(b)-offset >= len(p) && string(b[offset:offset+len(p)]) == p
+}
+
+// 6.2.3. Signature for MP3 without ID3
+func isMP3WithoutID3(b []byte) bool {
+	if !matchMP3Header(b, 0) {
+		return false
+	}
+	size := mp3FrameSize(b, 0)
+	if size < 4 || size > len(b) {
+		return false
+	}
+	return matchMP3Header(b, size)
+}
+
+var (
+	mp3Rates    = []int{0, 32000, 40000, 48000, 56000, 64000, 80000, 96000, 112000, 128000, 160000, 192000, 224000, 256000, 320000}
+	mp25Rates   = []int{0, 8000, 16000, 24000, 32000, 40000, 48000, 56000, 64000, 80000, 96000, 112000, 128000, 144000, 160000}
+	sampleRates = []int{44100, 48000, 32000}
+)
+
+// Reports whether a layer III frame header starts at the offset, per
+// section 6.2.3, "match an mp3 header".
+func matchMP3Header(b []byte, s int) bool {
+	if len(b)-s < 4 {
+		return false
+	}
+	if b[s] != 0xff || b[s+1]&0xe0 != 0xe0 {
+		return false
+	}
+	layer := (b[s+1] & 0x06) >> 1
+	if layer == 0 {
+		return false
+	}
+	if (b[s+2]&0xf0)>>4 == 15 {
+		return false // invalid bit rate
+	}
+	if (b[s+2]&0x0c)>>2 == 3 {
+		return false // invalid sample rate
+	}
+	return 4-layer == 3
+}
+
+// Returns the size of the frame whose header starts at the offset, per
+// section 6.2.3, "parse an mp3 frame" and "compute an mp3 frame size".
+func mp3FrameSize(b []byte, s int) int {
+	version := (b[s+1] & 0x18) >> 3
+	rates := mp3Rates
+	if version&0x01 != 0 {
+		rates = mp25Rates
+	}
+	bitRate := rates[(b[s+2]&0xf0)>>4]
+	freq := sampleRates[(b[s+2]&0x0c)>>2]
+	pad := int((b[s+2] & 0x02) >> 1)
+
+	scale := 144
+	if version == 1 {
+		scale = 72
+	}
+	size := bitRate * scale / freq
+	if pad != 0 {
+		size++
+	}
+	return size
+}
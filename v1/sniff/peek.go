@@ -0,0 +1,131 @@
+package sniff
+
+import (
+	"io"
+	"net/http"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// The sizes to which Peek grows its buffer while looking for a signature.
+var peekSteps = []int{16, 64, 512}
+
+// Reader reads content whose type has been detected from its leading bytes.
+// The bytes read for detection are not lost: reading from the Reader returns
+// the complete content.
+type Reader struct {
+	r      io.Reader
+	closer io.Closer
+	header []byte
+	unread []byte // the part of the header which has not been read
+	err    error  // the error which ended reading the header
+	t      mime.Type
+}
+
+// Wraps a reader and detects the type of its content with Unknown, reading
+// no more than limit bytes, or ResourceHeaderLen if limit is not positive.
+// Content which begins with a signature is identified as soon as enough
+// bytes to match it have been read; otherwise up to limit bytes are read.
+//
+// The Reader closes the wrapped reader if it is an io.Closer, so it can
+// replace an http.Request.Body. Errors reading the wrapped reader are
+// returned from Read once the buffered bytes have been consumed.
+func Peek(r io.Reader, limit int) *Reader {
+	if limit <= 0 {
+		limit = ResourceHeaderLen
+	}
+	p := &Reader{r: r}
+	if c, ok := r.(io.Closer); ok {
+		p.closer = c
+	}
+	p.t = p.peek(limit)
+	p.unread = p.header
+	return p
+}
+
+// Reads the header in steps, never reading more than the step requires, and
+// identifies the content as soon as a signature matches.
+func (p *Reader) peek(limit int) mime.Type {
+	buf := make([]byte, 0, limit)
+	for _, n := range append(append([]int(nil), peekSteps...), limit) {
+		if n > limit {
+			n = limit
+		}
+		if n <= len(buf) {
+			continue
+		}
+		m, err := io.ReadFull(p.r, buf[len(buf):n])
+		buf = buf[:len(buf)+m]
+		p.header = buf
+		if err != nil {
+			if err != io.EOF && err != io.ErrUnexpectedEOF {
+				p.err = err
+			} else {
+				p.err = io.EOF
+			}
+			break // the content ended
+		}
+		if len(buf) < limit {
+			if t, ok := signature(buf); ok {
+				return t
+			}
+		}
+	}
+	return Unknown(p.header, true)
+}
+
+// Matches content against the patterns Unknown uses which identify content
+// from a prefix, without the fallback to text or binary which needs the
+// whole header.
+func signature(b []byte) (mime.Type, bool) {
+	if t, ok := matchPatterns(scriptablePatterns, b); ok {
+		return t, true
+	}
+	if t, ok := matchPatterns(unscriptablePatterns, b); ok {
+		return t, true
+	}
+	if t, ok := Image(b); ok {
+		return t, true
+	}
+	if t, ok := AudioVideo(b); ok {
+		return t, true
+	}
+	return Archive(b)
+}
+
+// Returns the detected type of the content.
+func (p *Reader) Type() mime.Type {
+	return p.t
+}
+
+// Returns the bytes read to detect the type of the content.
+func (p *Reader) Header() []byte {
+	return p.header
+}
+
+// Determines the computed MIME type of the content given the headers it was
+// served with, as a browser would, from the bytes read to detect its type.
+func (p *Reader) Computed(h http.Header) mime.Type {
+	return Computed(h, p.header)
+}
+
+// Reads the content, starting with the bytes read to detect its type.
+func (p *Reader) Read(b []byte) (int, error) {
+	if len(p.unread) > 0 {
+		n := copy(b, p.unread)
+		p.unread = p.unread[n:]
+		return n, nil
+	}
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.r.Read(b)
+}
+
+// Closes the wrapped reader if it is an io.Closer.
+func (p *Reader) Close() error {
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+	return nil
+}
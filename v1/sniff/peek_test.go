@@ -0,0 +1,145 @@
+package sniff
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// countingReader records how many bytes have been read from it.
+type countingReader struct {
+	r      io.Reader
+	n      int
+	closed bool
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += n
+	return n, err
+}
+
+func (c *countingReader) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestPeek(t *testing.T) {
+	long := strings.Repeat("hello, world ", 1000)
+	testCases := []struct {
+		name   string
+		input  string
+		limit  int
+		result mime.Type
+		read   int
+	}{
+		{"PNG", pngData + long, 0, "image/png", 16},
+		{"HTML", "<!DOCTYPE html><html><body>" + long, 0, "text/html", 16},
+		{"HTML after whitespace", "\n\n<!DOCTYPE html><html><body>" + long, 0, "text/html", 64},
+		{"MP4", mp4Data + long, 0, "video/mp4", 64},
+		{"Text", long, 0, mime.Text, ResourceHeaderLen},
+		{"Text with limit", long, 100, mime.Text, 100},
+		{"Short text", "hello", 0, mime.Text, 5},
+		{"Binary", binData + long, 0, unknownBinary, ResourceHeaderLen},
+		{"Empty", "", 0, mime.Text, 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			source := &countingReader{r: strings.NewReader(testCase.input)}
+			reader := Peek(source, testCase.limit)
+			if reader.Type() != testCase.result {
+				t.Errorf("Unexpected type %q, expected %q", reader.Type(), testCase.result)
+			}
+			if source.n != testCase.read {
+				t.Errorf("Unexpected number of bytes read %d, expected %d", source.n, testCase.read)
+			}
+			if len(reader.Header()) != testCase.read {
+				t.Errorf("Unexpected header length %d, expected %d", len(reader.Header()), testCase.read)
+			}
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\"", err)
+			} else if string(data) != testCase.input {
+				t.Errorf("Content was not replayed, got %d bytes, expected %d", len(data), len(testCase.input))
+			}
+			if reader.Close(); !source.closed {
+				t.Errorf("Expected the source to be closed")
+			}
+		})
+	}
+}
+
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (f *failingReader) Read(b []byte) (int, error) {
+	if len(f.data) == 0 {
+		return 0, f.err
+	}
+	n := copy(b, f.data)
+	f.data = f.data[n:]
+	return n, nil
+}
+
+func TestPeekError(t *testing.T) {
+	failure := errors.New("connection reset")
+	reader := Peek(&failingReader{data: []byte("hello"), err: failure}, 0)
+	if reader.Type() != mime.Text {
+		t.Errorf("Unexpected type %q", reader.Type())
+	}
+	data, err := io.ReadAll(reader)
+	if string(data) != "hello" {
+		t.Errorf("Unexpected content %q", data)
+	}
+	if !errors.Is(err, failure) {
+		t.Errorf("Unexpected error \"%v\", expected \"%v\"", err, failure)
+	}
+}
+
+func TestPeekRequestBody(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := Peek(r.Body, 0)
+		r.Body = body
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", body.Type().String())
+		w.Write(data)
+	})
+
+	content := []byte(pngData + strings.Repeat("\x00", 2000))
+	request := httptest.NewRequest(http.MethodPost, "http://test.test", bytes.NewReader(content))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if v := recorder.Header().Get("Content-Type"); v != "image/png" {
+		t.Errorf("Unexpected type %q", v)
+	}
+	if !bytes.Equal(recorder.Body.Bytes(), content) {
+		t.Errorf("Body was not replayed, got %d bytes, expected %d", recorder.Body.Len(), len(content))
+	}
+}
+
+func TestPeekComputed(t *testing.T) {
+	reader := Peek(strings.NewReader(pngData), 0)
+	header := http.Header{"Content-Type": {"image/gif"}}
+	if result := reader.Computed(header); result != "image/png" {
+		t.Errorf("Unexpected computed type %q", result)
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+	if result := reader.Computed(header); result != "image/gif" {
+		t.Errorf("Unexpected computed type %q", result)
+	}
+}
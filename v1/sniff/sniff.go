@@ -0,0 +1,252 @@
+// Package sniff implements the WHATWG MIME Sniffing Standard, which
+// browsers use to decide how to treat a resource, so that servers can
+// predict what a browser will do with what they serve.
+//
+// See https://mimesniff.spec.whatwg.org/.
+package sniff
+
+import (
+	"net/http"
+	"strings"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// ResourceHeaderLen is the number of leading bytes of a resource the
+// algorithms examine, per section 5.2.
+const ResourceHeaderLen = 1445
+
+// 7.1. Identifying a resource with an unknown MIME type
+var (
+	scriptablePatterns = []pattern{
+		htmlTag("<!DOCTYPE HTML"),
+		htmlTag("<HTML"),
+		htmlTag("<HEAD"),
+		htmlTag("<SCRIPT"),
+		htmlTag("<IFRAME"),
+		htmlTag("<H1"),
+		htmlTag("<DIV"),
+		htmlTag("<FONT"),
+		htmlTag("<TABLE"),
+		htmlTag("<A"),
+		htmlTag("<STYLE"),
+		htmlTag("<TITLE"),
+		htmlTag("<B"),
+		htmlTag("<BODY"),
+		htmlTag("<BR"),
+		htmlTag("<P"),
+		htmlTag("<!--"),
+		{pattern: []byte("<?xml"), mask: []byte{0xff, 0xff, 0xff, 0xff, 0xff}, ignored: whitespaceBytes, result: "text/xml"},
+		exact("%PDF-", "application/pdf"),
+	}
+	unscriptablePatterns = []pattern{
+		exact("%!PS-Adobe-", "application/postscript"),
+		{pattern: []byte{0xfe, 0xff, 0, 0}, mask: []byte{0xff, 0xff, 0, 0}, result: mime.Text},
+		{pattern: []byte{0xff, 0xfe, 0, 0}, mask: []byte{0xff, 0xff, 0, 0}, result: mime.Text},
+		{pattern: []byte{0xef, 0xbb, 0xbf, 0}, mask: []byte{0xff, 0xff, 0xff, 0}, result: mime.Text},
+	}
+)
+
+// The type of resources which cannot be identified.
+const unknownBinary = mime.Type("application/octet-stream")
+
+func header(b []byte) []byte {
+	if len(b) > ResourceHeaderLen {
+		return b[:ResourceHeaderLen]
+	}
+	return b
+}
+
+// Identifies a resource whose type is unknown from its header, per section
+// 7.1. When sniffScriptable is false, HTML, XML and PDF are never produced.
+func Unknown(b []byte, sniffScriptable bool) mime.Type {
+	b = header(b)
+	if sniffScriptable {
+		if t, ok := matchPatterns(scriptablePatterns, b); ok {
+			return t
+		}
+	}
+	if t, ok := matchPatterns(unscriptablePatterns, b); ok {
+		return t
+	}
+	if t, ok := Image(b); ok {
+		return t
+	}
+	if t, ok := AudioVideo(b); ok {
+		return t
+	}
+	if t, ok := Archive(b); ok {
+		return t
+	}
+	if !hasBinaryData(b) {
+		return mime.Text
+	}
+	return unknownBinary
+}
+
+// Distinguishes text from binary resources, per section 7.2.
+func TextOrBinary(b []byte) mime.Type {
+	b = header(b)
+	if len(b) >= 2 && (string(b[:2]) == "\xfe\xff" || string(b[:2]) == "\xff\xfe") {
+		return mime.Text
+	}
+	if len(b) >= 3 && string(b[:3]) == "\xef\xbb\xbf" {
+		return mime.Text
+	}
+	if !hasBinaryData(b) {
+		return mime.Text
+	}
+	return Unknown(b, false)
+}
+
+func hasBinaryData(b []byte) bool {
+	for _, c := range b {
+		// 3. Terminology, binary data byte
+		if c <= 0x08 || c == 0x0b || (c >= 0x0e && c <= 0x1a) || (c >= 0x1c && c <= 0x1f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Content-Type values which Apache historically sent for any resource, so
+// which are treated as text or binary instead of being trusted, per section
+// 5.1.
+var apacheBugTypes = map[string]struct{}{
+	"text/plain":                     {},
+	"text/plain; charset=ISO-8859-1": {},
+	"text/plain; charset=iso-8859-1": {},
+	"text/plain; charset=UTF-8":      {},
+}
+
+// Determines the computed MIME type of a resource, per section 7, given its
+// Content-Type header value, or an empty string if it has none, whether it
+// was served with X-Content-Type-Options: nosniff, and its leading bytes.
+func ComputedType(contentType string, noSniff bool, b []byte) mime.Type {
+	var supplied *MIMEType
+	if contentType != "" {
+		if m, ok := extractMIMEType([]string{contentType}); ok {
+			supplied = &m
+		}
+	}
+	_, apacheBug := apacheBugTypes[contentType]
+	return computed(supplied, apacheBug, noSniff, header(b))
+}
+
+// Determines the computed MIME type of a resource from its response headers
+// and leading bytes.
+func Computed(h http.Header, b []byte) mime.Type {
+	var supplied *MIMEType
+	values := h.Values("Content-Type")
+	if m, ok := extractMIMEType(values); ok {
+		supplied = &m
+	}
+	apacheBug := false
+	if len(values) == 1 {
+		_, apacheBug = apacheBugTypes[values[0]]
+	}
+	return computed(supplied, apacheBug, NoSniff(h), header(b))
+}
+
+func computed(supplied *MIMEType, apacheBug, noSniff bool, b []byte) mime.Type {
+	if supplied == nil {
+		return Unknown(b, !noSniff)
+	}
+	switch supplied.Essence() {
+	case "unknown/unknown", "application/unknown", "*/*":
+		return Unknown(b, !noSniff)
+	}
+	if noSniff {
+		return supplied.Mime()
+	}
+	if apacheBug {
+		return TextOrBinary(b)
+	}
+	if supplied.IsXML() || supplied.IsHTML() {
+		return supplied.Mime()
+	}
+	if supplied.IsImage() {
+		if t, ok := Image(b); ok {
+			return t
+		}
+	}
+	if supplied.IsAudioOrVideo() {
+		if t, ok := AudioVideo(b); ok {
+			return t
+		}
+	}
+	return supplied.Mime()
+}
+
+// Reports whether the headers forbid sniffing, per the WHATWG Fetch
+// Standard, section 3.5, "determine nosniff".
+func NoSniff(h http.Header) bool {
+	values := splitHeader(h.Values("X-Content-Type-Options"))
+	return len(values) > 0 && strings.EqualFold(values[0], "nosniff")
+}
+
+// Extracts a MIME type from the values of a Content-Type header, per the
+// WHATWG Fetch Standard, section 3.1.7. A charset given with an earlier
+// value of the same essence is kept when a later value has none.
+func extractMIMEType(values []string) (MIMEType, bool) {
+	var (
+		result  MIMEType
+		found   bool
+		charset string
+		essence string
+	)
+	for _, e := range splitHeader(values) {
+		m, err := Parse(e)
+		if err != nil || m.Essence() == "*/*" {
+			continue
+		}
+		result, found = m.copy(), true
+		if m.Essence() != essence {
+			charset = ""
+			if v, ok := m.Param("charset"); ok {
+				charset = v
+			}
+			essence = m.Essence()
+		} else if _, ok := m.Param("charset"); !ok && charset != "" {
+			result.setParam("charset", charset)
+		}
+	}
+	return result, found
+}
+
+// Gets, decodes and splits the values of a header, per the WHATWG Fetch
+// Standard, section 2.2.2: commas inside quoted strings do not separate
+// values.
+func splitHeader(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	s := strings.Join(values, ", ")
+	var result []string
+	for {
+		b := &strings.Builder{}
+		for len(s) > 0 {
+			x := strings.IndexAny(s, "\",")
+			if x < 0 {
+				b.WriteString(s)
+				s = ""
+			} else if s[x] == '"' {
+				b.WriteString(s[:x])
+				start := s[x:]
+				_, rest := collectQuotedString(start)
+				b.WriteString(start[:len(start)-len(rest)])
+				s = rest
+				continue
+			} else {
+				b.WriteString(s[:x])
+				s = s[x:]
+			}
+			break
+		}
+		result = append(result, strings.Trim(b.String(), "\t "))
+		if len(s) == 0 {
+			return result
+		}
+		s = s[1:] // skip the comma
+	}
+}
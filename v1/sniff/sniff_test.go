@@ -0,0 +1,168 @@
+package sniff
+
+import (
+	"net/http"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+var (
+	pngData  = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	gifData  = "GIF89a\x01\x00\x01\x00\x80\x00\x00"
+	webpData = "RIFF\x24\x00\x00\x00WEBPVP8 "
+	mp4Data  = "\x00\x00\x00\x1cftypisom\x00\x00\x02\x00isomiso2mp41"
+	webmData = "\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\xf7\x81\x01\x42\xf2\x81\x04\x42\xf3\x81\x08\x42\x82\x84webm\x42\x87"
+	// The standard reads the bit rate of this MPEG-1 frame from its MPEG-2.5
+	// table, making the frame 80000 * 144 / 44100 = 261 bytes long.
+	mp3Data = "\xff\xfb\x90\x00" + string(make([]byte, 257)) + "\xff\xfb\x90\x00"
+	zipData = "PK\x03\x04\x14\x00\x00\x00"
+	binData = "\x00\x01\x02\x03\x04\x05"
+)
+
+func TestSignatures(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sniff  func([]byte) (mime.Type, bool)
+		input  string
+		result mime.Type
+	}{
+		{"PNG", Image, pngData, "image/png"},
+		{"GIF", Image, gifData, "image/gif"},
+		{"WebP", Image, webpData, "image/webp"},
+		{"JPEG", Image, "\xff\xd8\xff\xe0\x00\x10JFIF", "image/jpeg"},
+		{"Icon", Image, "\x00\x00\x01\x00\x01\x00", "image/x-icon"},
+		{"Not an image", Image, "hello", mime.Invalid},
+		{"MP4", AudioVideo, mp4Data, "video/mp4"},
+		{"WebM", AudioVideo, webmData, "video/webm"},
+		{"MP3 with ID3", AudioVideo, "ID3\x03\x00\x00\x00", "audio/mpeg"},
+		{"MP3 without ID3", AudioVideo, mp3Data, "audio/mpeg"},
+		{"Ogg", AudioVideo, "OggS\x00\x02\x00\x00", "application/ogg"},
+		{"WAVE", AudioVideo, "RIFF\x24\x00\x00\x00WAVEfmt ", "audio/wave"},
+		{"AVI", AudioVideo, "RIFF\x24\x00\x00\x00AVI LIST", "video/avi"},
+		{"Truncated MP4", AudioVideo, mp4Data[:10], mime.Invalid},
+		{"WOFF2", Font, "wOF2\x00\x01\x00\x00", "font/woff2"},
+		{"TrueType", Font, "\x00\x01\x00\x00\x00\x0e", "font/ttf"},
+		{"Embedded OpenType", Font, string(make([]byte, 34)) + "LP", "application/vnd.ms-fontobject"},
+		{"Zip", Archive, zipData, "application/zip"},
+		{"Gzip", Archive, "\x1f\x8b\x08\x00", "application/x-gzip"},
+		{"RAR", Archive, "Rar \x1a\x07\x00", "application/x-rar-compressed"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, ok := testCase.sniff([]byte(testCase.input))
+			if ok != (testCase.result != mime.Invalid) || result != testCase.result {
+				t.Errorf("Unexpected result %q (%v), expected %q", result, ok, testCase.result)
+			}
+		})
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		scriptable bool
+		result     mime.Type
+	}{
+		{"HTML", "<!DOCTYPE html><html>", true, "text/html"},
+		{"HTML after whitespace", " \n\t<HTML>", true, "text/html"},
+		{"HTML tag needs terminator", "<htmlx>", true, mime.Text},
+		{"Comment", "<!-- x -->", true, "text/html"},
+		{"Unscriptable HTML", "<html>", false, mime.Text},
+		{"XML", "<?xml version=\"1.0\"?>", true, "text/xml"},
+		{"PDF", "%PDF-1.7", true, "application/pdf"},
+		{"Unscriptable PDF", "%PDF-1.7\x00", false, unknownBinary},
+		{"PostScript", "%!PS-Adobe-3.0", false, "application/postscript"},
+		{"UTF-16 BOM", "\xff\xfeh\x00i\x00", true, mime.Text},
+		{"Image", pngData, true, "image/png"},
+		{"Video", mp4Data, true, "video/mp4"},
+		{"Archive", zipData, true, "application/zip"},
+		{"Text", "Hello, world", true, mime.Text},
+		{"Binary", binData, true, unknownBinary},
+		{"Empty", "", true, mime.Text},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if result := Unknown([]byte(testCase.input), testCase.scriptable); result != testCase.result {
+				t.Errorf("Unexpected result %q, expected %q", result, testCase.result)
+			}
+		})
+	}
+}
+
+func TestTextOrBinary(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		result mime.Type
+	}{
+		{"Text", "Hello, world", mime.Text},
+		{"UTF-8 BOM with binary", "\xef\xbb\xbf\x00\x01", mime.Text},
+		{"HTML is text", "<html>", mime.Text},
+		{"Image", pngData, "image/png"},
+		{"Binary", binData, unknownBinary},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if result := TextOrBinary([]byte(testCase.input)); result != testCase.result {
+				t.Errorf("Unexpected result %q, expected %q", result, testCase.result)
+			}
+		})
+	}
+}
+
+func TestComputed(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType []string
+		nosniff     string
+		input       string
+		result      mime.Type
+	}{
+		{"No type", nil, "", "<html>", "text/html"},
+		{"No type with nosniff", nil, "nosniff", "<html>", mime.Text},
+		{"Unknown type", []string{"unknown/unknown"}, "", pngData, "image/png"},
+		{"Wildcard", []string{"*/*"}, "", "<html>", "text/html"},
+		{"Supplied type", []string{"application/json"}, "", "<html>", "application/json"},
+		{"Normalized", []string{"Application/JSON; Charset=\"utf-8\""}, "", "{}", "application/json;charset=utf-8"},
+		{"Nosniff", []string{"image/gif"}, "nosniff", pngData, "image/gif"},
+		{"Nosniff with other values", []string{"image/gif"}, "NoSniff, other", pngData, "image/gif"},
+		{"Nosniff not first", []string{"image/gif"}, "other, nosniff", pngData, "image/png"},
+		{"Apache bug text", []string{"text/plain"}, "", "<html>", mime.Text},
+		{"Apache bug binary", []string{"text/plain; charset=UTF-8"}, "", pngData, "image/png"},
+		{"Not the Apache bug", []string{"text/plain;charset=utf-8"}, "", pngData, "text/plain;charset=utf-8"},
+		{"HTML is trusted", []string{"text/html"}, "", pngData, "text/html"},
+		{"XML is trusted", []string{"image/svg+xml"}, "", pngData, "image/svg+xml"},
+		{"Image is sniffed", []string{"image/gif"}, "", pngData, "image/png"},
+		{"Unrecognized image", []string{"image/gif"}, "", "hello", "image/gif"},
+		{"Video is sniffed", []string{"video/mp4"}, "", webmData, "video/webm"},
+		{"Last type wins", []string{"text/html", "image/png"}, "", "hello", "image/png"},
+		{"Charset is carried", []string{"text/html;charset=gbk", "text/html"}, "", "hello", "text/html;charset=gbk"},
+		{"Invalid types skipped", []string{"text/html, bogus"}, "", "hello", "text/html"},
+		{"Invalid type", []string{"bogus"}, "", "<html>", "text/html"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			header := make(http.Header)
+			for _, e := range testCase.contentType {
+				header.Add("Content-Type", e)
+			}
+			if testCase.nosniff != "" {
+				header.Set("X-Content-Type-Options", testCase.nosniff)
+			}
+			if result := Computed(header, []byte(testCase.input)); result != testCase.result {
+				t.Errorf("Unexpected result %q, expected %q", result, testCase.result)
+			}
+			if len(testCase.contentType) == 1 {
+				if result := ComputedType(testCase.contentType[0], NoSniff(header), []byte(testCase.input)); result != testCase.result {
+					t.Errorf("Unexpected result from ComputedType %q, expected %q", result, testCase.result)
+				}
+			}
+		})
+	}
+}
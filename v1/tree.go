@@ -0,0 +1,147 @@
+package mime
+
+import (
+	"strings"
+)
+
+// Tree identifies the RFC 6838 registration tree a type belongs to.
+type Tree string
+
+const (
+	TreeStandard     = Tree("standard")
+	TreeVendor       = Tree(FacetVendor)
+	TreePersonal     = Tree(FacetPersonal)
+	TreeUnregistered = Tree(FacetUnregistered)
+)
+
+func (t Tree) String() string {
+	return string(t)
+}
+
+// Application types whose content is text even though their top-level
+// type is not; types with a textual structured syntax suffix are handled
+// separately.
+var textApplicationTypes = map[string]struct{}{
+	"json":                       {},
+	"xml":                        {},
+	"javascript":                 {},
+	"ecmascript":                 {},
+	"x-javascript":               {},
+	"x-www-form-urlencoded":      {},
+	"yaml":                       {},
+	"x-yaml":                     {},
+	"toml":                       {},
+	"sql":                        {},
+	"graphql":                    {},
+	"x-sh":                       {},
+	"x-httpd-php":                {},
+	"rtf":                        {},
+	"x-tex":                      {},
+	"x-latex":                    {},
+	"mbox":                       {},
+	"xml-dtd":                    {},
+	"xml-external-parsed-entity": {},
+}
+
+// Structured syntax suffixes whose content is text.
+var textSuffixes = map[string]struct{}{
+	"json":     {},
+	"json-seq": {},
+	"xml":      {},
+	"yaml":     {},
+	"toml":     {},
+}
+
+// split returns the lower-cased top-level type and subtype of the base type,
+// or reports false if the type is not of the form type/subtype.
+func (t Type) split() (string, string, bool) {
+	s := strings.ToLower(t.Base().String())
+	x := strings.Index(s, "/")
+	if x < 1 || x == len(s)-1 {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[:x]), strings.TrimSpace(s[x+1:]), true
+}
+
+// TopLevel returns the top-level type, like "text" for text/plain, or an
+// empty string if the type is invalid.
+func (t Type) TopLevel() string {
+	top, _, _ := t.split()
+	return top
+}
+
+// Subtype returns the subtype without any parameters, like "vnd.acme+json"
+// for application/vnd.acme+json, or an empty string if the type is invalid.
+func (t Type) Subtype() string {
+	_, sub, _ := t.split()
+	return sub
+}
+
+// Suffix returns the structured syntax suffix of the subtype, like "json" for
+// application/vnd.acme+json, or an empty string if it has none.
+func (t Type) Suffix() string {
+	sub := t.Subtype()
+	if x := strings.LastIndex(sub, "+"); x >= 0 {
+		return sub[x+1:]
+	}
+	return ""
+}
+
+// Tree returns the registration tree of the type according to the facet its
+// subtype starts with. Types without a facet are in the standards tree. The
+// historical "x-" prefix is treated as the unregistered tree.
+func (t Type) Tree() Tree {
+	sub := t.Subtype()
+	switch {
+	case strings.HasPrefix(sub, FacetVendor+"."):
+		return TreeVendor
+	case strings.HasPrefix(sub, FacetPersonal+"."):
+		return TreePersonal
+	case strings.HasPrefix(sub, FacetUnregistered+"."), strings.HasPrefix(sub, FacetUnregistered+"-"):
+		return TreeUnregistered
+	default:
+		return TreeStandard
+	}
+}
+
+// IsWildcard reports whether the type is a media range such as */* or text/*
+// rather than a concrete type.
+func (t Type) IsWildcard() bool {
+	top, sub, ok := t.split()
+	return ok && (top == "*" || sub == "*")
+}
+
+// IsText reports whether content of the type is text: any text/* type, types
+// with a textual suffix like +json or +xml, and a set of well known textual
+// application types such as application/json.
+func (t Type) IsText() bool {
+	top, sub, ok := t.split()
+	if !ok || t.IsWildcard() {
+		return false
+	}
+	if top == "text" {
+		return true
+	}
+	if _, ok := textSuffixes[t.Suffix()]; ok {
+		return true
+	}
+	if top == "application" {
+		_, ok := textApplicationTypes[sub]
+		return ok
+	}
+	return false
+}
+
+// IsMultipart reports whether the type is a multipart/* type.
+func (t Type) IsMultipart() bool {
+	top, _, ok := t.split()
+	return ok && top == "multipart" && !t.IsWildcard()
+}
+
+// IsBinary reports whether content of the type is binary. Concrete types are
+// binary unless they are text or multipart; wildcards and invalid types are
+// neither text nor binary.
+func (t Type) IsBinary() bool {
+	_, _, ok := t.split()
+	return ok && !t.IsWildcard() && !t.IsText() && !t.IsMultipart()
+}
@@ -0,0 +1,108 @@
+package mime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	tests := []struct {
+		In                        Type
+		TopLevel, Subtype, Suffix string
+		Tree                      Tree
+		Wildcard, Text, Binary    bool
+		Multipart                 bool
+	}{
+		{
+			In:       Type("text/plain;charset=utf-8"),
+			TopLevel: "text", Subtype: "plain",
+			Tree: TreeStandard,
+			Text: true,
+		},
+		{
+			In:       Type("Application/JSON"),
+			TopLevel: "application", Subtype: "json",
+			Tree: TreeStandard,
+			Text: true,
+		},
+		{
+			In:       Type("application/vnd.acme.v3+json"),
+			TopLevel: "application", Subtype: "vnd.acme.v3+json", Suffix: "json",
+			Tree: TreeVendor,
+			Text: true,
+		},
+		{
+			In:       Type("image/svg+xml"),
+			TopLevel: "image", Subtype: "svg+xml", Suffix: "xml",
+			Tree: TreeStandard,
+			Text: true,
+		},
+		{
+			In:       Type("image/png"),
+			TopLevel: "image", Subtype: "png",
+			Tree:   TreeStandard,
+			Binary: true,
+		},
+		{
+			In:       Type("application/vnd.ms-excel"),
+			TopLevel: "application", Subtype: "vnd.ms-excel",
+			Tree:   TreeVendor,
+			Binary: true,
+		},
+		{
+			In:       Type("text/prs.fallenstein.rst"),
+			TopLevel: "text", Subtype: "prs.fallenstein.rst",
+			Tree: TreePersonal,
+			Text: true,
+		},
+		{
+			In:       Type("application/x-tar"),
+			TopLevel: "application", Subtype: "x-tar",
+			Tree:   TreeUnregistered,
+			Binary: true,
+		},
+		{
+			In:       Type("application/x.acme+zip"),
+			TopLevel: "application", Subtype: "x.acme+zip", Suffix: "zip",
+			Tree:   TreeUnregistered,
+			Binary: true,
+		},
+		{
+			In:       Type("multipart/form-data; boundary=x"),
+			TopLevel: "multipart", Subtype: "form-data",
+			Tree:      TreeStandard,
+			Multipart: true,
+		},
+		{
+			In:       Type("text/*"),
+			TopLevel: "text", Subtype: "*",
+			Tree:     TreeStandard,
+			Wildcard: true,
+		},
+		{
+			In:       Type("*/*"),
+			TopLevel: "*", Subtype: "*",
+			Tree:     TreeStandard,
+			Wildcard: true,
+		},
+		{
+			In:   Invalid,
+			Tree: TreeStandard,
+		},
+		{
+			In:   Type("text/"),
+			Tree: TreeStandard,
+		},
+	}
+	for i, e := range tests {
+		assert.Equal(t, e.TopLevel, e.In.TopLevel(), "#%d", i)
+		assert.Equal(t, e.Subtype, e.In.Subtype(), "#%d", i)
+		assert.Equal(t, e.Suffix, e.In.Suffix(), "#%d", i)
+		assert.Equal(t, e.Tree, e.In.Tree(), "#%d", i)
+		assert.Equal(t, e.Wildcard, e.In.IsWildcard(), "#%d", i)
+		assert.Equal(t, e.Text, e.In.IsText(), "#%d", i)
+		assert.Equal(t, e.Binary, e.In.IsBinary(), "#%d", i)
+		assert.Equal(t, e.Multipart, e.In.IsMultipart(), "#%d", i)
+	}
+}
@@ -0,0 +1,290 @@
+// Package upload decides whether uploaded files are acceptable by comparing
+// the type they claim to be with the type their content reveals, and finds
+// content which is dangerous to serve back to browsers.
+package upload
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// SniffLen is the number of leading bytes of the content which are examined.
+const SniffLen = 512
+
+// Verdict summarizes how the declared type, the filename extension and the
+// content of an upload agree.
+type Verdict string
+
+const (
+	// The declared type, extension and content agree.
+	Match = Verdict("match")
+	// The claims disagree with each other, or the content is text where
+	// binary is claimed or the reverse, but nothing identifies the content
+	// as some other specific type.
+	Mismatch = Verdict("mismatch")
+	// The content is identified as a specific type which disagrees with the
+	// declared type or the extension.
+	Spoofed = Verdict("spoofed")
+	// There is not enough information to compare: fewer than two of the
+	// declared type, extension and content identify a type.
+	Unknown = Verdict("unknown")
+)
+
+func (v Verdict) String() string {
+	return string(v)
+}
+
+func (v Verdict) rank() int {
+	switch v {
+	case Spoofed:
+		return 3
+	case Mismatch:
+		return 2
+	case Unknown:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Result describes the outcome of checking an upload.
+type Result struct {
+	Verdict   Verdict
+	Declared  mime.Type    // the declared type without parameters and with aliases resolved, or Invalid
+	Extension mime.Options // the types associated with the filename extension
+	Sniffed   mime.Type    // the type identified from the content, or Invalid if there is none
+	Type      mime.Type    // the type the upload is most likely to be
+	Allowed   bool         // whether the policy accepts the upload
+	Reasons   []string     // why the verdict was reached or the upload rejected
+}
+
+func (r *Result) reason(format string, args ...any) {
+	r.Reasons = append(r.Reasons, fmt.Sprintf(format, args...))
+}
+
+func (r *Result) escalate(v Verdict) {
+	if v.rank() > r.Verdict.rank() {
+		r.Verdict = v
+	}
+}
+
+// Policy decides which uploads are acceptable. The zero value accepts
+// uploads of any type whose verdict is Match.
+type Policy struct {
+	// Types which are accepted; if empty every type not denied is. Options
+	// may be wildcards, like image/*.
+	Allow mime.Options
+	// Types which are rejected. An upload is rejected if its declared type,
+	// any of its extension types or its sniffed type is denied.
+	Deny mime.Options
+	// Accept uploads whose verdict is Mismatch.
+	AllowMismatch bool
+	// Accept uploads whose verdict is Unknown.
+	AllowUnknown bool
+}
+
+// Check examines an upload given its Content-Type header, its filename and
+// the leading bytes of its content, of which at most SniffLen are used.
+// Uploads whose verdict is Spoofed are always rejected.
+func (p Policy) Check(contentType, filename string, content []byte) Result {
+	r := Result{Verdict: Match}
+
+	evidence := 0
+	if contentType != "" {
+		if t, _, err := mime.Parse(contentType); err == nil {
+			r.Declared = canonical(t.Base())
+			evidence++
+		} else {
+			r.reason("declared type %q is invalid: %v", contentType, err)
+		}
+	}
+	ext := path.Ext(filename)
+	if ext != "" {
+		r.Extension = mime.TypesByExtension(ext)
+		if len(r.Extension) > 0 {
+			evidence++
+		}
+	}
+	if len(content) > SniffLen {
+		content = content[:SniffLen]
+	}
+	if len(content) > 0 {
+		r.Sniffed = sniff(content)
+		evidence++
+	}
+
+	if isSpecific(r.Sniffed) {
+		if r.Declared != mime.Invalid && !consistent(r.Declared, r.Sniffed) {
+			r.escalate(Spoofed)
+			r.reason("content is %s but is declared as %s", r.Sniffed, r.Declared)
+		}
+		if len(r.Extension) > 0 && !anyConsistent(r.Extension, r.Sniffed) {
+			r.escalate(Spoofed)
+			r.reason("content is %s but extension %s indicates %s", r.Sniffed, ext, r.Extension.First(mime.Invalid))
+		}
+	} else if r.Sniffed != mime.Invalid {
+		text := r.Sniffed == mime.Text
+		for _, e := range append(mime.Options{r.Declared}, r.Extension...) {
+			if e == mime.Invalid || e == unknownBinary || e.IsMultipart() {
+				continue
+			}
+			if text && e.IsBinary() {
+				r.escalate(Mismatch)
+				r.reason("content is text but %s is binary", e)
+				break
+			} else if !text && e.IsText() {
+				r.escalate(Mismatch)
+				r.reason("content is binary but %s is text", e)
+				break
+			}
+		}
+	}
+	if r.Declared != mime.Invalid && r.Declared != unknownBinary && len(r.Extension) > 0 && !r.Extension.Match(r.Declared) {
+		r.escalate(Mismatch)
+		r.reason("declared type %s disagrees with extension %s", r.Declared, ext)
+	}
+	if evidence < 2 {
+		r.escalate(Unknown)
+		r.reason("not enough information to compare types")
+	}
+
+	switch {
+	case isSpecific(r.Sniffed):
+		r.Type = r.Sniffed
+	case r.Declared != mime.Invalid && r.Verdict != Mismatch:
+		r.Type = r.Declared
+	case r.Sniffed != mime.Invalid:
+		r.Type = r.Sniffed
+	case r.Declared != mime.Invalid:
+		r.Type = r.Declared
+	case len(r.Extension) > 0:
+		r.Type = r.Extension[0]
+	default:
+		r.Type = unknownBinary
+	}
+
+	r.Allowed = p.allow(&r)
+	return r
+}
+
+func (p Policy) allow(r *Result) bool {
+	switch {
+	case r.Verdict == Spoofed:
+		return false
+	case r.Verdict == Mismatch && !p.AllowMismatch:
+		r.reason("mismatched uploads are not allowed")
+		return false
+	case r.Verdict == Unknown && !p.AllowUnknown:
+		r.reason("unidentified uploads are not allowed")
+		return false
+	}
+	for _, e := range append(mime.Options{r.Declared, r.Sniffed, r.Type}, r.Extension...) {
+		if e != mime.Invalid && p.Deny.Match(e) {
+			r.reason("%s is denied", e)
+			return false
+		}
+	}
+	if len(p.Allow) > 0 && !p.Allow.Match(r.Type) {
+		r.reason("%s is not allowed", r.Type)
+		return false
+	}
+	return true
+}
+
+// The type http.DetectContentType reports when it cannot identify binary
+// content.
+const unknownBinary = mime.Type("application/octet-stream")
+
+// sniff identifies the type of the content, without parameters.
+func sniff(content []byte) mime.Type {
+	if len(content) == 0 {
+		return unknownBinary
+	}
+	return canonical(mime.Type(http.DetectContentType(content)).Base())
+}
+
+// Names for types other than the ones in the registry, which
+// http.DetectContentType reports or browsers declare.
+var aliases = map[mime.Type]mime.Type{
+	"application/x-gzip":           "application/gzip",
+	"application/x-zip-compressed": "application/zip",
+	"audio/wav":                    "audio/vnd.wave",
+	"audio/wave":                   "audio/vnd.wave",
+	"audio/x-wav":                  "audio/vnd.wave",
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"image/x-icon":                 "image/vnd.microsoft.icon",
+}
+
+// canonical returns the registered name of a type which may be an alias.
+func canonical(t mime.Type) mime.Type {
+	if e, ok := aliases[mime.Type(strings.ToLower(t.String()))]; ok {
+		return e
+	}
+	return t
+}
+
+// isSpecific reports whether the sniffed type identifies the content more
+// precisely than as text or binary.
+func isSpecific(t mime.Type) bool {
+	return t != mime.Invalid && t != mime.Text && t != unknownBinary
+}
+
+// Types whose content is a container identified by its sniffed type.
+var containers = map[mime.Type]mime.Options{
+	"application/zip": {
+		"application/*+zip",
+		"application/java-archive",
+		"application/vnd.android.package-archive",
+		"application/vnd.oasis.opendocument.presentation",
+		"application/vnd.oasis.opendocument.spreadsheet",
+		"application/vnd.oasis.opendocument.text",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	},
+	"application/ogg": {"audio/ogg", "video/ogg", "audio/opus", "audio/vorbis"},
+	"video/webm":      {"audio/webm"},
+	"video/mp4":       {"audio/mp4", "application/mp4"},
+	"text/xml":        {"application/xml", "application/*+xml", "image/*+xml", "text/*+xml"},
+	"text/html":       {"application/xhtml+xml"},
+}
+
+// consistent reports whether content sniffed as one type may be of the
+// claimed type. Types the registry records as compatible, like text/xml and
+// application/xml, are consistent. mime.Compatible is not used, since it
+// would make all text consistent with text/plain and so let HTML pass as
+// plain text.
+func consistent(claimed, sniffed mime.Type) bool {
+	claimed = canonical(claimed)
+	if claimed.Matches(sniffed) {
+		return true
+	}
+	if claimed.Matches(unknownBinary) {
+		return sniffed.IsBinary() // a generic claim for any binary content
+	}
+	if registeredCompatible(claimed, sniffed) || registeredCompatible(sniffed, claimed) {
+		return true
+	}
+	return containers[sniffed].Match(claimed)
+}
+
+// registeredCompatible reports whether the registry lists one type as
+// compatible with the other.
+func registeredCompatible(from, to mime.Type) bool {
+	reg, ok := mime.Lookup(from)
+	return ok && reg.Compatible.Match(to)
+}
+
+func anyConsistent(claimed mime.Options, sniffed mime.Type) bool {
+	for _, e := range claimed {
+		if consistent(e, sniffed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package upload
+
+import (
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+var (
+	pngData  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+	pdfData  = []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n1 0 obj\n")
+	zipData  = []byte("PK\x03\x04\x14\x00\x06\x00\x08\x00\x00\x00!\x00")
+	htmlData = []byte("<!DOCTYPE html><html><body><script>alert(1)</script></body></html>")
+	csvData  = []byte("name,age\nalice,30\nbob,40\n")
+	binData  = []byte("\x00\x01\x02\x03\x04\x05\x06\x07\xff\xfe\xfd")
+	jpegData = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01")
+	wavData  = []byte("RIFF\x24\x08\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x02\x00")
+	xmlData  = []byte("<?xml version=\"1.0\"?><feed></feed>")
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		filename    string
+		content     []byte
+		verdict     Verdict
+		result      mime.Type
+	}{
+		{"Image", "image/png", "photo.png", pngData, Match, "image/png"},
+		{"Image with parameters", "image/png; foo=bar", "photo.PNG", pngData, Match, "image/png"},
+		{"Document", "application/pdf", "report.pdf", pdfData, Match, "application/pdf"},
+		{"Zip container", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", "report.docx", zipData, Match, "application/zip"},
+		{"Text", "text/csv", "data.csv", csvData, Match, "text/csv"},
+		{"Text without filename", "text/csv", "", csvData, Match, "text/csv"},
+		{"Generic binary", "application/octet-stream", "photo.png", pngData, Match, "image/png"},
+		{"Alias image/jpg", "image/jpg", "photo.jpg", jpegData, Match, "image/jpeg"},
+		{"Alias image/pjpeg", "image/pjpeg", "photo.jpeg", jpegData, Match, "image/jpeg"},
+		{"Alias audio/wav", "audio/wav", "sound.wav", wavData, Match, "audio/vnd.wave"},
+		{"Alias audio/x-wav", "audio/x-wav", "sound.wav", wavData, Match, "audio/vnd.wave"},
+		{"Alias audio/wave", "audio/wave", "sound.wav", wavData, Match, "audio/vnd.wave"},
+		{"Alias application/x-zip-compressed", "application/x-zip-compressed", "archive.zip", zipData, Match, "application/zip"},
+		{"Registered compatible types", "application/xml", "feed.xml", xmlData, Match, "text/xml"},
+		{"Extension disagrees", "image/png", "photo.gif", pngData, Spoofed, "image/png"},
+		{"Declared type disagrees", "image/gif", "photo.png", pngData, Spoofed, "image/png"},
+		{"HTML declared as text", "text/plain", "notes.txt", htmlData, Spoofed, "text/html"},
+		{"HTML declared as image", "image/png", "photo.png", htmlData, Spoofed, "text/html"},
+		{"Executable declared as text", "text/csv", "report.exe", csvData, Mismatch, "text/plain"},
+		{"Text declared as binary", "application/pdf", "report.pdf", csvData, Mismatch, "text/plain"},
+		{"Binary declared as text", "text/csv", "data.csv", binData, Mismatch, "application/octet-stream"},
+		{"Claims disagree", "text/csv", "data.json", csvData, Mismatch, "text/plain"},
+		{"Only content", "", "", pngData, Unknown, "image/png"},
+		{"Only declared type", "text/csv", "", nil, Unknown, "text/csv"},
+		{"Nothing", "", "", nil, Unknown, "application/octet-stream"},
+		{"Invalid declared type", "text?csv", "data", csvData, Unknown, "text/plain"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := Policy{}.Check(testCase.contentType, testCase.filename, testCase.content)
+			if result.Verdict != testCase.verdict {
+				t.Errorf("Unexpected verdict %s, expected %s; reasons: %v", result.Verdict, testCase.verdict, result.Reasons)
+			}
+			if result.Type != testCase.result {
+				t.Errorf("Unexpected type %s, expected %s", result.Type, testCase.result)
+			}
+			if result.Allowed != (testCase.verdict == Match) {
+				t.Errorf("Unexpected allowed %v for verdict %s; reasons: %v", result.Allowed, result.Verdict, result.Reasons)
+			}
+			if result.Verdict != Match && len(result.Reasons) == 0 {
+				t.Errorf("Expected reasons for verdict %s", result.Verdict)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      Policy
+		contentType string
+		filename    string
+		content     []byte
+		allowed     bool
+	}{
+		{"Allowed wildcard", Policy{Allow: mime.Options{"image/*"}}, "image/png", "photo.png", pngData, true},
+		{"Not allowed", Policy{Allow: mime.Options{"image/*"}}, "application/pdf", "report.pdf", pdfData, false},
+		{"Denied", Policy{Deny: mime.Options{"application/pdf"}}, "application/pdf", "report.pdf", pdfData, false},
+		{"Denied wildcard", Policy{Deny: mime.Options{"text/*"}}, "text/csv", "data.csv", csvData, false},
+		{"Denied extension", Policy{Deny: mime.Options{"application/vnd.microsoft.portable-executable"}, AllowMismatch: true}, "text/csv", "report.exe", csvData, false},
+		{"Denied declared type", Policy{Deny: mime.Options{"text/html"}, AllowMismatch: true}, "text/html", "page.txt", csvData, false},
+		{"Mismatch allowed", Policy{AllowMismatch: true}, "text/csv", "data.json", csvData, true},
+		{"Unknown allowed", Policy{AllowUnknown: true}, "", "", pngData, true},
+		{"Unknown not allowed by type", Policy{Allow: mime.Options{"text/*"}, AllowUnknown: true}, "", "", pngData, false},
+		{"Spoofed never allowed", Policy{AllowMismatch: true, AllowUnknown: true}, "image/gif", "photo.gif", pngData, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := testCase.policy.Check(testCase.contentType, testCase.filename, testCase.content)
+			if result.Allowed != testCase.allowed {
+				t.Errorf("Unexpected allowed %v, expected %v; verdict %s, reasons: %v", result.Allowed, testCase.allowed, result.Verdict, result.Reasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+// DefaultScanLimit is the number of bytes of content Scan examines.
+const DefaultScanLimit = 1 << 20
+
+// Kind identifies what a scan found.
+type Kind string
+
+const (
+	// HTML markup in content which is not claimed to be HTML, which a
+	// browser that sniffs the content may render.
+	KindHTML = Kind("html")
+	// Script or event handlers in HTML, SVG or other XML content.
+	KindScript = Kind("script")
+	// An XML document type declaration which defines external entities.
+	KindExternalEntity = Kind("external-entity")
+	// JavaScript or automatic actions in a PDF document.
+	KindPDFAction = Kind("pdf-action")
+	// Content which is valid as more than one format, like an image which
+	// is also HTML or has a zip archive appended.
+	KindPolyglot = Kind("polyglot")
+)
+
+func (k Kind) String() string {
+	return string(k)
+}
+
+// Finding describes something dangerous found in content.
+type Finding struct {
+	Kind    Kind
+	Offset  int64 // the offset in the content at which it was found
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s at %d: %s", f.Kind, f.Offset, f.Message)
+}
+
+// Report describes the outcome of scanning content.
+type Report struct {
+	Claimed  mime.Type // the type the content claims to be
+	Detected mime.Type // the type detected from the leading bytes of the content
+	Findings []Finding
+}
+
+func (r *Report) find(kind Kind, offset int, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{Kind: kind, Offset: int64(offset), Message: fmt.Sprintf(format, args...)})
+}
+
+// Dangerous reports whether anything dangerous was found.
+func (r Report) Dangerous() bool {
+	return len(r.Findings) > 0
+}
+
+// Has reports whether the scan found something of the given kind.
+func (r Report) Has(kind Kind) bool {
+	for _, e := range r.Findings {
+		if e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SetHeaders sets the headers content should be served with: browsers are
+// always told not to sniff it, and content which is dangerous is served as
+// an attachment, so that it is downloaded instead of rendered.
+func (r Report) SetHeaders(h http.Header, filename string) {
+	h.Set("X-Content-Type-Options", "nosniff")
+	if r.Dangerous() {
+		h.Set("Content-Disposition", mime.Attachment(filename, r.Claimed).String())
+	}
+}
+
+// A Scanner finds polyglots and active content.
+type Scanner struct {
+	// The number of bytes of content to examine; if zero, DefaultScanLimit.
+	Limit int64
+}
+
+// Scan examines content using a Scanner with the default limit.
+func Scan(r io.Reader, claimed mime.Type) (Report, error) {
+	return Scanner{}.Scan(r, claimed)
+}
+
+var (
+	// Elements which make a browser render content as HTML, per the WHATWG
+	// MIME Sniffing Standard, section 7.1, and elements which load or run
+	// other content.
+	htmlPattern = regexp.MustCompile(`(?i)<(!doctype\s+html|html|head|body|script|iframe|frame|frameset|object|embed|svg|img|meta|style|link|form|base|title|!--)[\s/>]`)
+	// Script elements, event handler attributes and script URIs.
+	scriptPattern = regexp.MustCompile(`(?i)<script[\s/>]|<[^>]*\son[a-z]+\s*=|(java|vb)script\s*:|<foreignobject[\s/>]|<handler[\s/>]`)
+	// Entities declared with a system or public identifier.
+	entityPattern = regexp.MustCompile(`(?i)<!entity\s+(%\s+)?[^\s>]+\s+(system|public)\s`)
+	// PDF names which run JavaScript or act when the document is opened.
+	pdfActionPattern = regexp.MustCompile(`/(JavaScript|JS|OpenAction|AA|Launch|EmbeddedFile|SubmitForm|RichMedia|XFA)[\s/<>\[\](]`)
+)
+
+// Scan reads up to the scanner's limit of content and reports polyglots and
+// active content given the type the content claims to be.
+func (s Scanner) Scan(r io.Reader, claimed mime.Type) (Report, error) {
+	limit := s.Limit
+	if limit <= 0 {
+		limit = DefaultScanLimit
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit))
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Claimed: claimed.Base()}
+	if len(data) == 0 {
+		return report, nil
+	}
+	report.Detected = sniff(data)
+
+	html := claimed.Matches(mime.HTML) || claimed.Matches("application/xhtml+xml")
+	xml := claimed.Suffix() == "xml" || claimed.Subtype() == "xml" || report.Detected.Matches(mime.XML)
+	pdf := claimed.Matches("application/pdf") || report.Detected.Matches("application/pdf")
+
+	if !html {
+		if x := htmlPattern.FindIndex(data); x != nil {
+			if isSpecific(report.Detected) && !report.Detected.IsText() {
+				report.find(KindPolyglot, x[0], "%s content contains HTML markup", report.Detected)
+			} else if !xml {
+				report.find(KindHTML, x[0], "%s content contains HTML markup", report.claimedOrDetected())
+			}
+		}
+	}
+	if html || xml || report.Detected.Matches(mime.HTML) {
+		if x := scriptPattern.FindIndex(data); x != nil {
+			report.find(KindScript, x[0], "%s content contains script", report.claimedOrDetected())
+		}
+	}
+	if xml || bytes.Contains(data, []byte("<!")) {
+		if x := entityPattern.FindIndex(data); x != nil {
+			report.find(KindExternalEntity, x[0], "document type declares an external entity")
+		}
+	}
+	if pdf {
+		if x := pdfActionPattern.FindSubmatchIndex(data); x != nil {
+			report.find(KindPDFAction, x[0], "PDF document contains /%s", data[x[2]:x[3]])
+		}
+	}
+	if isSpecific(report.Detected) && !report.Detected.IsText() {
+		for _, e := range embeddedSignatures {
+			if e.detected.Matches(report.Detected) {
+				continue
+			}
+			if x := bytes.Index(data[1:], e.signature); x >= 0 {
+				report.find(KindPolyglot, x+1, "%s content contains %s", report.Detected, e.detected)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (r Report) claimedOrDetected() mime.Type {
+	if r.Claimed != mime.Invalid {
+		return r.Claimed
+	}
+	return r.Detected
+}
+
+// Signatures of formats which are recognized even when they do not start at
+// the beginning of the content: readers locate a zip archive from its end
+// and PDF readers search for the header.
+var embeddedSignatures = []struct {
+	detected  mime.Type
+	signature []byte
+}{
+	{"application/zip", []byte("PK\x03\x04")},
+	{"application/pdf", []byte("%PDF-")},
+}
@@ -0,0 +1,98 @@
+package upload
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	mime "github.com/bww/go-mime/v1"
+)
+
+func TestScan(t *testing.T) {
+	gifData := []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00\xff\xff\xff\x00\x00\x00!\xf9\x04\x01\x00\x00\x00\x00,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;")
+	testCases := []struct {
+		name    string
+		claimed mime.Type
+		content string
+		kinds   []Kind
+	}{
+		{"Plain text", mime.Text, "Hello, world. 1 < 2 and 3 > 2.", nil},
+		{"HTML in plain text", mime.Text, "Hello\n<script>alert(1)</script>", []Kind{KindHTML}},
+		{"HTML in CSV", mime.CSV, "name,value\n<iframe src=//evil>,1\n", []Kind{KindHTML}},
+		{"HTML document", mime.HTML, "<!DOCTYPE html><html><body><p>Hello</p></body></html>", nil},
+		{"HTML document with script", mime.HTML, "<!DOCTYPE html><html><body onload=\"go()\"></body></html>", []Kind{KindScript}},
+		{"Image", "image/gif", string(gifData), nil},
+		{"Image and HTML", "image/gif", string(gifData) + "<html><script>alert(1)</script></html>", []Kind{KindPolyglot}},
+		{"Image and zip", "image/gif", string(gifData) + "PK\x03\x04\x14\x00\x00\x00", []Kind{KindPolyglot}},
+		{"Image claimed as text", mime.Text, string(gifData) + "<script>alert(1)</script>", []Kind{KindPolyglot}},
+		{"SVG", "image/svg+xml", `<svg xmlns="http://www.w3.org/2000/svg"><circle r="1"/></svg>`, nil},
+		{"SVG with script", "image/svg+xml", `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`, []Kind{KindScript}},
+		{"SVG with event handler", "image/svg+xml", `<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"></svg>`, []Kind{KindScript}},
+		{"SVG with script URI", "image/svg+xml", `<svg><a href="javascript:alert(1)"><text>x</text></a></svg>`, []Kind{KindScript}},
+		{"XML", mime.XML, `<?xml version="1.0"?><doc>text</doc>`, nil},
+		{"XML external entity", "application/xml", `<?xml version="1.0"?><!DOCTYPE doc [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><doc>&xxe;</doc>`, []Kind{KindExternalEntity}},
+		{"XML parameter entity", mime.XML, `<?xml version="1.0"?><!DOCTYPE doc [<!ENTITY % p PUBLIC "x" "http://evil/x.dtd"> %p;]><doc/>`, []Kind{KindExternalEntity}},
+		{"XML internal entity", mime.XML, `<?xml version="1.0"?><!DOCTYPE doc [<!ENTITY name "value">]><doc>&name;</doc>`, nil},
+		{"PDF", "application/pdf", "%PDF-1.7\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n", nil},
+		{"PDF with JavaScript", "application/pdf", "%PDF-1.7\n1 0 obj\n<< /Type /Catalog /OpenAction 3 0 R >>\nendobj\n3 0 obj\n<< /S /JavaScript /JS (app.alert(1)) >>\n", []Kind{KindPDFAction}},
+		{"Empty", mime.Text, "", nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			report, err := Scan(strings.NewReader(testCase.content), testCase.claimed)
+			if err != nil {
+				t.Errorf("Unexpected error \"%s\"", err)
+				return
+			}
+			var kinds []Kind
+			for _, e := range report.Findings {
+				kinds = append(kinds, e.Kind)
+			}
+			if !reflect.DeepEqual(kinds, testCase.kinds) {
+				t.Errorf("Unexpected findings %v, expected %v", report.Findings, testCase.kinds)
+			}
+			if report.Dangerous() != (len(testCase.kinds) > 0) {
+				t.Errorf("Unexpected dangerous %v", report.Dangerous())
+			}
+		})
+	}
+}
+
+func TestScanLimit(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 100)
+	content = append(content, "<script>alert(1)</script>"...)
+
+	report, err := Scanner{Limit: 100}.Scan(bytes.NewReader(content), mime.Text)
+	if err != nil {
+		t.Errorf("Unexpected error \"%s\"", err)
+	} else if report.Dangerous() {
+		t.Errorf("Unexpected findings beyond the limit: %v", report.Findings)
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	testCases := []struct {
+		name        string
+		report      Report
+		disposition string
+	}{
+		{"Safe", Report{Claimed: mime.Text}, ""},
+		{"Dangerous", Report{Claimed: mime.Text, Findings: []Finding{{Kind: KindHTML}}}, "attachment; filename=notes.txt"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			header := make(http.Header)
+			testCase.report.SetHeaders(header, "notes")
+			if v := header.Get("X-Content-Type-Options"); v != "nosniff" {
+				t.Errorf("Unexpected X-Content-Type-Options %q", v)
+			}
+			if v := header.Get("Content-Disposition"); v != testCase.disposition {
+				t.Errorf("Unexpected Content-Disposition %q, expected %q", v, testCase.disposition)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package mime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bww/go-mime/v1/internal/lex"
+)
+
+// Strictness controls which RFC 6838 rules Validate enforces. Each level
+// includes the rules of the levels below it.
+type Strictness int
+
+const (
+	// Relaxed checks syntax only: the type/subtype form, name lengths, the
+	// restricted-name characters and well formed parameters.
+	Relaxed Strictness = iota
+	// Standard also requires a registered top-level type, parameter names
+	// which are restricted-names and no duplicate parameters.
+	Standard
+	// Strict also rejects practices RFC 6838 discourages: a charset parameter
+	// on types which are not text and subtypes with the "x-" prefix.
+	Strict
+)
+
+// Rule identifies the requirement a type violates.
+type Rule string
+
+const (
+	RuleSyntax             = Rule("syntax")
+	RuleLength             = Rule("length")
+	RuleRestrictedName     = Rule("restricted-name")
+	RuleParameterSyntax    = Rule("parameter-syntax")
+	RuleTopLevel           = Rule("top-level")
+	RuleParameterName      = Rule("parameter-name")
+	RuleDuplicateParameter = Rule("duplicate-parameter")
+	RuleCharset            = Rule("charset")
+	RuleUnregisteredPrefix = Rule("unregistered-prefix")
+)
+
+// The longest type or subtype name RFC 6838 allows.
+const maxNameLength = 127
+
+// Top-level types registered with IANA.
+var topLevelTypes = map[string]struct{}{
+	"application": {},
+	"audio":       {},
+	"example":     {},
+	"font":        {},
+	"haptics":     {},
+	"image":       {},
+	"message":     {},
+	"model":       {},
+	"multipart":   {},
+	"text":        {},
+	"video":       {},
+}
+
+// Violation describes one way in which a type fails validation.
+type Violation struct {
+	Rule    Rule
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// ValidationError lists every violation found in a type.
+type ValidationError struct {
+	Type       Type
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "invalid media type %q: ", e.Type.String())
+	for i, v := range e.Violations {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+// Validate checks the type against the rules of RFC 6838 at the given
+// strictness and returns a *ValidationError describing every violation, or
+// nil if the type is valid.
+func Validate(t Type, strictness Strictness) error {
+	v := &validator{strictness: strictness}
+	v.validate(t.String())
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Type: t, Violations: v.violations}
+}
+
+type validator struct {
+	strictness Strictness
+	violations []Violation
+}
+
+func (v *validator) fail(r Rule, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Rule: r, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(s string) {
+	base, params := s, ""
+	if x := strings.Index(s, ";"); x >= 0 {
+		base, params = s[:x], s[x:]
+	}
+	base = strings.TrimSpace(base)
+
+	x := strings.Index(base, "/")
+	if x < 0 {
+		v.fail(RuleSyntax, "expected type/subtype, found %q", base)
+		return
+	}
+	top, sub := base[:x], base[x+1:]
+	v.validateName("type", top)
+	v.validateName("subtype", sub)
+
+	top, sub = strings.ToLower(top), strings.ToLower(sub)
+	if v.strictness >= Standard {
+		if _, ok := topLevelTypes[top]; !ok && top != "" {
+			v.fail(RuleTopLevel, "%q is not a registered top-level type", top)
+		}
+	}
+	if v.strictness >= Strict {
+		if strings.HasPrefix(sub, FacetUnregistered+"-") {
+			v.fail(RuleUnregisteredPrefix, "subtype %q uses the deprecated \"x-\" prefix", sub)
+		}
+	}
+
+	v.validateParameters(top, sub, params)
+}
+
+// validateName checks a type or subtype name against the restricted-name
+// production of RFC 6838, section 4.2.
+func (v *validator) validateName(what, name string) {
+	if len(name) == 0 {
+		v.fail(RuleSyntax, "%s is empty", what)
+		return
+	}
+	if len(name) > maxNameLength {
+		v.fail(RuleLength, "%s %q is longer than %d characters", what, name, maxNameLength)
+	}
+	if !lex.IsRestrictedNameFirstChar(name[0]) {
+		v.fail(RuleRestrictedName, "%s %q must start with a letter or digit", what, name)
+	}
+	for i := 1; i < len(name); i++ {
+		if !lex.IsRestrictedNameChar(name[i]) {
+			v.fail(RuleRestrictedName, "%s %q contains invalid character %q", what, name, name[i])
+			break
+		}
+	}
+}
+
+func (v *validator) validateParameters(top, sub, s string) {
+	seen := make(map[string]struct{})
+	for len(s) > 0 {
+		s = s[1:] // skip the semicolon
+		var param string
+		param, s = splitParameter(s)
+		param = strings.TrimSpace(param)
+
+		x := strings.Index(param, "=")
+		if x < 1 {
+			v.fail(RuleParameterSyntax, "expected name=value, found %q", param)
+			continue
+		}
+		name, value := strings.TrimSpace(param[:x]), strings.TrimSpace(param[x+1:])
+		if !isParameterValue(value) {
+			v.fail(RuleParameterSyntax, "parameter %q has invalid value %q", name, value)
+		}
+
+		lname := strings.ToLower(name)
+		if v.strictness >= Standard {
+			if len(name) > maxNameLength || !isRestrictedName(name) {
+				v.fail(RuleParameterName, "parameter name %q is not a restricted-name", name)
+			}
+			if _, ok := seen[lname]; ok {
+				v.fail(RuleDuplicateParameter, "parameter %q is specified more than once", name)
+			}
+		}
+		seen[lname] = struct{}{}
+
+		if v.strictness >= Strict && lname == "charset" && top != "text" && !isXML(sub) {
+			v.fail(RuleCharset, "charset parameter on non-text type %s/%s", top, sub)
+		}
+	}
+}
+
+// XML types define their own charset parameter in RFC 7303.
+func isXML(sub string) bool {
+	return sub == "xml" || strings.HasSuffix(sub, "+xml")
+}
+
+// splitParameter returns the text up to the next semicolon which is not part
+// of a quoted string, and the remainder starting at that semicolon.
+func splitParameter(s string) (string, string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == ';':
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
+
+func isRestrictedName(s string) bool {
+	if len(s) == 0 || !lex.IsRestrictedNameFirstChar(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !lex.IsRestrictedNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isParameterValue reports whether the value is a token or a complete
+// quoted string, per RFC 2045, section 5.1.
+func isParameterValue(s string) bool {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		for i := 1; i < len(s)-1; i++ {
+			if s[i] == '\\' {
+				if i+1 >= len(s)-1 {
+					return false // the closing quote is escaped
+				}
+				i++
+			} else if s[i] == '"' || s[i] < 0x20 || s[i] == 0x7f {
+				return false
+			}
+		}
+		return true
+	}
+	return lex.IsToken(s)
+}
@@ -0,0 +1,58 @@
+package mime
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	long := strings.Repeat("a", 128)
+	tests := []struct {
+		In         Type
+		Strictness Strictness
+		Rules      []Rule
+	}{
+		{In: Text, Strictness: Strict},
+		{In: Type("application/vnd.acme.v3+json; profile=\"https://example.com/v3\""), Strictness: Strict},
+		{In: Type("text/plain;charset=utf-8"), Strictness: Strict},
+		{In: Type("application/xhtml+xml;charset=utf-8"), Strictness: Strict},
+		{In: Type("text"), Strictness: Relaxed, Rules: []Rule{RuleSyntax}},
+		{In: Type("/plain"), Strictness: Relaxed, Rules: []Rule{RuleSyntax}},
+		{In: Type("text/" + long), Strictness: Relaxed, Rules: []Rule{RuleLength}},
+		{In: Type("text/.plain"), Strictness: Relaxed, Rules: []Rule{RuleRestrictedName}},
+		{In: Type("te*t/pl%in"), Strictness: Relaxed, Rules: []Rule{RuleRestrictedName, RuleRestrictedName}},
+		{In: Type("text/plain;charset"), Strictness: Relaxed, Rules: []Rule{RuleParameterSyntax}},
+		{In: Type("text/plain;a=b c"), Strictness: Relaxed, Rules: []Rule{RuleParameterSyntax}},
+		{In: Type("text/plain;a=\"b;c\""), Strictness: Relaxed},
+		{In: Type("text/plain;a=\"b\\\""), Strictness: Relaxed, Rules: []Rule{RuleParameterSyntax}},
+		{In: Type("bogus/plain"), Strictness: Relaxed},
+		{In: Type("bogus/plain"), Strictness: Standard, Rules: []Rule{RuleTopLevel}},
+		{In: Type("text/plain;a=1;A=2"), Strictness: Relaxed},
+		{In: Type("text/plain;a=1;A=2"), Strictness: Standard, Rules: []Rule{RuleDuplicateParameter}},
+		{In: Type("text/plain;_a=1"), Strictness: Standard, Rules: []Rule{RuleParameterName}},
+		{In: Type("application/json;charset=utf-8"), Strictness: Standard},
+		{In: Type("application/json;charset=utf-8"), Strictness: Strict, Rules: []Rule{RuleCharset}},
+		{In: Type("application/x-tar"), Strictness: Standard},
+		{In: Type("application/x-tar"), Strictness: Strict, Rules: []Rule{RuleUnregisteredPrefix}},
+		{In: Type("bogus/x-" + long + ";charset=a;charset=b"), Strictness: Strict, Rules: []Rule{RuleLength, RuleTopLevel, RuleUnregisteredPrefix, RuleCharset, RuleDuplicateParameter, RuleCharset}},
+	}
+	for i, e := range tests {
+		err := Validate(e.In, e.Strictness)
+		if len(e.Rules) == 0 {
+			assert.NoError(t, err, "#%d", i)
+			continue
+		}
+		var verr *ValidationError
+		if assert.True(t, errors.As(err, &verr), "#%d", i) {
+			rules := make([]Rule, len(verr.Violations))
+			for j, v := range verr.Violations {
+				rules[j] = v.Rule
+			}
+			assert.Equal(t, e.Rules, rules, "#%d: %v", i, err)
+			assert.Equal(t, e.In, verr.Type, "#%d", i)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package mime
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Facets of the RFC 6838 registration trees other than the standards tree.
+const (
+	FacetVendor       = "vnd"
+	FacetPersonal     = "prs"
+	FacetUnregistered = "x"
+)
+
+// Vendor describes a type registered in the vendor, personal or unregistered
+// tree, such as application/vnd.acme.orders.v3+json.
+type Vendor struct {
+	Facet   string // the tree facet, e.g. "vnd"
+	Product string // the producer and product name, e.g. "acme.orders"
+	Version string // the version, e.g. "v3", or empty if there is none
+	Suffix  string // the structured syntax suffix, e.g. "json", or empty
+}
+
+// Vendor decomposes a type in the vendor ("vnd."), personal ("prs.") or
+// unregistered ("x." or the historical "x-") trees. The version is taken from
+// the last name segments when they look like "v3" or "v3.1", and otherwise
+// from a "version" parameter if the type has one.
+func (t Type) Vendor() (Vendor, bool) {
+	tree := t.Tree()
+	if tree == TreeStandard {
+		return Vendor{}, false
+	}
+
+	v := Vendor{Facet: string(tree)}
+	sub := t.Subtype()[len(v.Facet)+1:]
+	if x := strings.LastIndex(sub, "+"); x >= 0 {
+		v.Suffix, sub = sub[x+1:], sub[:x]
+	}
+
+	segments := strings.Split(sub, ".")
+	for i := len(segments) - 1; i > 0; i-- {
+		if isVersionSegment(segments[i]) {
+			v.Version = strings.Join(segments[i:], ".")
+			segments = segments[:i]
+			break
+		} else if !isNumeric(segments[i]) {
+			break
+		}
+	}
+	v.Product = strings.Join(segments, ".")
+	if v.Product == "" {
+		return Vendor{}, false
+	}
+	if v.Version == "" {
+		if _, params, err := Parse(t.String()); err == nil {
+			v.Version = params["version"]
+		}
+	}
+
+	return v, true
+}
+
+func isNumeric(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isVersionSegment(s string) bool {
+	return len(s) > 1 && s[0] == 'v' && isNumeric(s[1:])
+}
+
+// Numbers returns the numeric components of the version, so "v3.1" and "3.1"
+// both produce [3, 1]. It reports false if the version is not numeric.
+func (v Vendor) Numbers() ([]int, bool) {
+	if v.Version == "" {
+		return nil, false
+	}
+	parts := strings.Split(strings.TrimPrefix(v.Version, "v"), ".")
+	n := make([]int, len(parts))
+	for i, e := range parts {
+		d, err := strconv.Atoi(e)
+		if err != nil || d < 0 {
+			return nil, false
+		}
+		n[i] = d
+	}
+	return n, true
+}
+
+// SameProduct reports whether two vendor types describe the same product in
+// the same tree with the same suffix, regardless of version.
+func (v Vendor) SameProduct(o Vendor) bool {
+	return v.Facet == o.Facet && v.Product == o.Product && v.Suffix == o.Suffix
+}
+
+// CompareVersion compares the versions of two vendor types, returning -1, 0
+// or 1. Numeric versions are compared component by component, so v10 is
+// newer than v9; an unversioned type is older than any version, and other
+// versions are compared as strings.
+func (v Vendor) CompareVersion(o Vendor) int {
+	a, aok := v.Numbers()
+	b, bok := o.Numbers()
+	switch {
+	case aok && bok:
+		for i := 0; i < len(a) || i < len(b); i++ {
+			var x, y int
+			if i < len(a) {
+				x = a[i]
+			}
+			if i < len(b) {
+				y = b[i]
+			}
+			if x != y {
+				if x < y {
+					return -1
+				}
+				return 1
+			}
+		}
+		return 0
+	case v.Version == "" || o.Version == "":
+		if v.Version == o.Version {
+			return 0
+		} else if v.Version == "" {
+			return -1
+		}
+		return 1
+	default:
+		return strings.Compare(v.Version, o.Version)
+	}
+}
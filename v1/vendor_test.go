@@ -0,0 +1,85 @@
+package mime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVendor(t *testing.T) {
+	tests := []struct {
+		In     Type
+		Vendor Vendor
+		OK     bool
+	}{
+		{
+			In:     Type("application/vnd.acme.v3+json"),
+			Vendor: Vendor{Facet: "vnd", Product: "acme", Version: "v3", Suffix: "json"},
+			OK:     true,
+		},
+		{
+			In:     Type("application/vnd.acme.orders.v3.1+json;charset=utf-8"),
+			Vendor: Vendor{Facet: "vnd", Product: "acme.orders", Version: "v3.1", Suffix: "json"},
+			OK:     true,
+		},
+		{
+			In:     Type("application/vnd.api+json;version=2"),
+			Vendor: Vendor{Facet: "vnd", Product: "api", Version: "2", Suffix: "json"},
+			OK:     true,
+		},
+		{
+			In:     Type("application/vnd.ms-excel"),
+			Vendor: Vendor{Facet: "vnd", Product: "ms-excel"},
+			OK:     true,
+		},
+		{
+			In:     Type("text/prs.fallenstein.rst"),
+			Vendor: Vendor{Facet: "prs", Product: "fallenstein.rst"},
+			OK:     true,
+		},
+		{
+			In:     Type("application/x-www-form-urlencoded"),
+			Vendor: Vendor{Facet: "x", Product: "www-form-urlencoded"},
+			OK:     true,
+		},
+		{
+			In:     Type("application/vnd.v3+json"),
+			Vendor: Vendor{Facet: "vnd", Product: "v3", Suffix: "json"},
+			OK:     true,
+		},
+		{
+			In: JSON,
+		},
+		{
+			In: Type("application/vnd."),
+		},
+		{
+			In: Invalid,
+		},
+	}
+	for i, e := range tests {
+		v, ok := e.In.Vendor()
+		assert.Equal(t, e.OK, ok, "#%d", i)
+		assert.Equal(t, e.Vendor, v, "#%d", i)
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		A, B   string
+		Expect int
+	}{
+		{"v3", "v3", 0},
+		{"v3", "3.0", 0},
+		{"v9", "v10", -1},
+		{"v3.1", "v3", 1},
+		{"", "v1", -1},
+		{"v1", "", 1},
+		{"", "", 0},
+		{"beta", "alpha", 1},
+	}
+	for i, e := range tests {
+		a, b := Vendor{Version: e.A}, Vendor{Version: e.B}
+		assert.Equal(t, e.Expect, a.CompareVersion(b), "#%d", i)
+	}
+}